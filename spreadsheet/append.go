@@ -0,0 +1,51 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// InsertDataOption describes how appended data changes existing data on the sheet
+type InsertDataOption string
+
+const (
+	// InsertRows inserts new rows for the appended data
+	InsertRows InsertDataOption = "INSERT_ROWS"
+	// Overwrite writes appended data over the rows after the table
+	Overwrite InsertDataOption = "OVERWRITE"
+)
+
+// AppendRows appends rows after the last row of the table found in name.
+// If raw is true values are stored as is, otherwise they are parsed as if
+// user typed them into the UI. New rows are inserted with InsertRows option.
+func AppendRows(srv *sheets.Service, id, name string, rows [][]interface{}, raw bool) error {
+	return AppendRowsWithOption(srv, id, name, rows, raw, InsertRows)
+}
+
+// AppendRowsWithOption is like AppendRows but allows to choose how appended
+// data changes existing data on the sheet
+func AppendRowsWithOption(
+	srv *sheets.Service,
+	id, name string,
+	rows [][]interface{},
+	raw bool,
+	opt InsertDataOption,
+) error {
+	input := "USER_ENTERED"
+	if raw {
+		input = "RAW"
+	}
+
+	vr := &sheets.ValueRange{Values: rows}
+
+	_, err := srv.Spreadsheets.Values.Append(id, name, vr).
+		ValueInputOption(input).
+		InsertDataOption(string(opt)).
+		Do()
+	if err != nil {
+		return fmt.Errorf("append rows: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package spreadsheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestAppendRows(t *testing.T) {
+	// parameters of the last append request
+	var input, insert, rng string
+	var body sheets.ValueRange
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutSuffix(r.URL.Path, ":append")
+		if _, name, found := strings.Cut(name, "/values/"); ok && found && r.Method == http.MethodPost {
+			rng = name
+			input = r.URL.Query().Get("valueInputOption")
+			insert = r.URL.Query().Get("insertDataOption")
+
+			body = sheets.ValueRange{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			json.NewEncoder(w).Encode(&sheets.AppendValuesResponse{})
+			return
+		}
+		http.Error(w, "unexpected request: "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+	})
+
+	rows := [][]interface{}{{"name", "age"}, {"alice", 30}}
+
+	if err := AppendRows(srv, "id", "Sheet1!A1:B1", rows, false); err != nil {
+		t.Fatalf("AppendRows() error: %v", err)
+	}
+
+	if rng != "Sheet1!A1:B1" || input != "USER_ENTERED" || insert != "INSERT_ROWS" {
+		t.Errorf("AppendRows() requested (%s, %s, %s), want (Sheet1!A1:B1, USER_ENTERED, INSERT_ROWS)",
+			rng, input, insert)
+	}
+
+	if res := fmt.Sprint(body.Values); res != "[[name age] [alice 30]]" {
+		t.Errorf("AppendRows() sent %s, want [[name age] [alice 30]]", res)
+	}
+
+	if err := AppendRowsWithOption(srv, "id", "Sheet1", rows[1:], true, Overwrite); err != nil {
+		t.Fatalf("AppendRowsWithOption() error: %v", err)
+	}
+
+	if rng != "Sheet1" || input != "RAW" || insert != "OVERWRITE" {
+		t.Errorf("AppendRowsWithOption() requested (%s, %s, %s), want (Sheet1, RAW, OVERWRITE)",
+			rng, input, insert)
+	}
+
+	if res := fmt.Sprint(body.Values); res != "[[alice 30]]" {
+		t.Errorf("AppendRowsWithOption() sent %s, want [[alice 30]]", res)
+	}
+}
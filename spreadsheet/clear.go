@@ -0,0 +1,48 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// ClearRange clears values of the given range and returns range that
+// was cleared as reported by the API.
+// Only values are cleared, formatting and validation rules are kept.
+// Whole sheet and open ranges (e.g Sheet1! or A1:B) are cleared up to
+// the grid edge, returned range has the bounds of the grid.
+func ClearRange(srv *sheets.Service, id string, r Range) (Range, error) {
+	resp, err := srv.Spreadsheets.Values.Clear(
+		id, r.apiName(), &sheets.ClearValuesRequest{},
+	).Do()
+	if err != nil {
+		return EmptyRange, fmt.Errorf("clear range: %w", accessError(id, err))
+	}
+
+	cleared, err := parseClearedRange(resp.ClearedRange)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("clear range: %v", err)
+	}
+
+	return cleared, nil
+}
+
+// parseClearedRange parses range reported by the API, single cell is
+// reported without the range end (e.g Sheet1!A1)
+func parseClearedRange(str string) (Range, error) {
+	if r, err := NewRange(str); err == nil {
+		return r, nil
+	}
+
+	sheet, addr, err := splitSheet(str)
+	if err != nil {
+		return EmptyRange, err
+	}
+
+	c, err := NewCellAddr(addr)
+	if err != nil {
+		return EmptyRange, err
+	}
+
+	return Range{Min: c, Max: c, Sheet: sheet}, nil
+}
@@ -0,0 +1,64 @@
+package spreadsheet
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestClearRange(t *testing.T) {
+	// cleared ranges as the API reports them by requested range
+	cleared := map[string]string{
+		"Sheet1!A1:B2":    "Sheet1!A1:B2",
+		"Sheet1!C3:C3":    "Sheet1!C3",
+		"Sheet1!":         "Sheet1!A1:Z1000",
+		"'My sheet'!A1:B": "'My sheet'!A1:B1000",
+	}
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		rng, ok := strings.CutSuffix(r.URL.Path, ":clear")
+		if _, rng, found := strings.Cut(rng, "/values/"); ok && found && r.Method == http.MethodPost {
+			if res, ok := cleared[rng]; ok {
+				json.NewEncoder(w).Encode(&sheets.ClearValuesResponse{ClearedRange: res})
+				return
+			}
+		}
+		http.Error(w, "unexpected request: "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+	})
+
+	tt := []struct {
+		rng  string
+		want string
+	}{
+		{"Sheet1!A1:B2", "Sheet1!A1:B2"},
+		{"Sheet1!C3:C3", "Sheet1!C3:C3"},
+		{"Sheet1!", "Sheet1!A1:Z1000"},
+		{"'My sheet'!A1:B", "'My sheet'!A1:B1000"},
+		// workbook of the Excel reference is not sent
+		{"[Book1.xlsx]Sheet1!A1:B2", "Sheet1!A1:B2"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		res, err := ClearRange(srv, "id", r)
+		if err != nil {
+			t.Errorf("ClearRange(%s) error: %v", tc.rng, err)
+			continue
+		}
+
+		if res.String() != tc.want {
+			t.Errorf("ClearRange(%s) = %v, want %s", tc.rng, res, tc.want)
+		}
+	}
+
+	if res, err := ClearRange(srv, "id", Range{Max: CellAddr{1, 1}, Sheet: "Sheet2"}); err == nil {
+		t.Errorf("ClearRange(Sheet2!A1:B2) = %v, want error", res)
+	}
+}
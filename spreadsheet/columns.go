@@ -0,0 +1,305 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DateLayout is a default layout of date values
+const DateLayout = "2006-01-02"
+
+// inferRows is a number of data rows used to infer column types
+const inferRows = 100
+
+// ColumnKind is a kind of column values
+type ColumnKind int
+
+const (
+	// KindAuto means that kind is inferred from the column values
+	KindAuto ColumnKind = iota
+	// KindString is a text column
+	KindString
+	// KindInt is an integer column
+	KindInt
+	// KindFloat is a floating point number column
+	KindFloat
+	// KindBool is a boolean column (e.g TRUE, FALSE)
+	KindBool
+	// KindDate is a date column
+	KindDate
+	// KindEmpty is a column without values
+	KindEmpty
+)
+
+// String implements fmt.Stringer interface
+func (k ColumnKind) String() string {
+	switch k {
+	case KindAuto:
+		return "auto"
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "bool"
+	case KindDate:
+		return "date"
+	case KindEmpty:
+		return "empty"
+	}
+	return "ColumnKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// ColumnType describes type of the column values
+type ColumnType struct {
+	Kind ColumnKind
+	// Layout is a time layout of KindDate values, DateLayout used if empty
+	Layout string
+}
+
+// layout returns time layout of the date values
+func (t ColumnType) layout() string {
+	if t.Layout == "" {
+		return DateLayout
+	}
+	return t.Layout
+}
+
+// Parse parses non empty value s according to the column type, result is
+// one of string, int64, float64, bool or time.Time
+func (t ColumnType) Parse(s string) (interface{}, error) {
+	switch t.Kind {
+	case KindAuto, KindString, KindEmpty:
+		return s, nil
+	case KindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case KindFloat:
+		return strconv.ParseFloat(s, 64)
+	case KindBool:
+		return strconv.ParseBool(s)
+	case KindDate:
+		return time.Parse(t.layout(), s)
+	}
+	return nil, fmt.Errorf("unknown column kind %v", t.Kind)
+}
+
+// format returns canonical representation of the value parsed by Parse:
+// integers and floats without separators and exponent, booleans as TRUE
+// or FALSE and dates in the layout of the column type
+func (t ColumnType) format(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case time.Time:
+		return v.Format(t.layout())
+	}
+	return fmt.Sprint(v)
+}
+
+// coerceRow replaces non empty values of the row with their canonical
+// representation according to the column types, columns of the row that
+// have no type or have KindAuto are left as is
+func coerceRow(row []string, types []ColumnType) error {
+	for i, s := range row {
+		if i >= len(types) {
+			break
+		}
+
+		if s == "" || types[i].Kind == KindAuto {
+			continue
+		}
+
+		v, err := types[i].Parse(s)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", string(colRunes(i+1)), err)
+		}
+
+		row[i] = types[i].format(v)
+	}
+
+	return nil
+}
+
+// inferKinds is an order in which kinds are tried during inference,
+// from the most specific to the least
+var inferKinds = [...]ColumnKind{KindInt, KindFloat, KindBool, KindDate}
+
+// inferColumnType returns the most specific type that matches all non
+// empty values of the column, column without values is a string column
+func inferColumnType(values []string) ColumnType {
+	for _, kind := range inferKinds {
+		t, ok := ColumnType{Kind: kind}, false
+
+		for _, v := range values {
+			if v == "" {
+				continue
+			}
+
+			if ok = t.matches(v); !ok {
+				break
+			}
+		}
+
+		if ok {
+			return t
+		}
+	}
+
+	return ColumnType{Kind: KindString}
+}
+
+// matches reports if non empty value s is inferred as a value of the
+// column type. It is stricter than Parse: numbers are decimals as Sheets
+// parses them without leading zeros (e.g 007 is an identifier, not
+// a number) and booleans are only TRUE or FALSE.
+func (t ColumnType) matches(s string) bool {
+	switch t.Kind {
+	case KindInt, KindFloat:
+		if !regexpDecimal.MatchString(s) || hasLeadingZero(s) {
+			return false
+		}
+	case KindBool:
+		return s == "TRUE" || s == "FALSE"
+	}
+
+	_, err := t.Parse(s)
+	return err == nil
+}
+
+// hasLeadingZero reports if integer part of the decimal number s has
+// a leading zero (e.g 007 or -01.5)
+func hasLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 1 && s[0] == '0' && s[1] >= '0' && s[1] <= '9'
+}
+
+// InferColumnTypes returns types of width columns of rows, types given
+// in override are used as is unless they are KindAuto, the rest are
+// inferred from the first n rows. Inferred column is widened to
+// KindString if any of the later rows has a value that does not match
+// its type, so every value of rows can be parsed with returned types.
+func InferColumnTypes(rows [][]string, width, n int, override []ColumnType) []ColumnType {
+	head := rows
+	if len(head) > n {
+		head = head[:n]
+	}
+
+	types := make([]ColumnType, width)
+	values := make([]string, 0, len(head))
+
+	for i := range types {
+		if i < len(override) && override[i].Kind != KindAuto {
+			types[i] = override[i]
+			continue
+		}
+
+		values = values[:0]
+		for _, row := range head {
+			if i < len(row) {
+				values = append(values, row[i])
+			}
+		}
+
+		types[i] = inferColumnType(values)
+
+		for _, row := range rows[len(head):] {
+			if i < len(row) && row[i] != "" && !types[i].matches(row[i]) {
+				types[i] = ColumnType{Kind: KindString}
+				break
+			}
+		}
+	}
+
+	return types
+}
+
+// ColumnStats describes values of the copied column
+type ColumnStats struct {
+	// Name is a column header, empty if there is no header
+	Name string
+	// Kind is the most common kind of the column values, numbers are
+	// KindFloat if there are both integers and floats in the column,
+	// it is KindEmpty if column has no values
+	Kind ColumnKind
+	// MaxLen is the maximum length of the column values in runes
+	MaxLen int
+	// Nulls is a number of the empty column values
+	Nulls int
+}
+
+// statsBuilder collects ColumnStats of the rows
+type statsBuilder struct {
+	stats  []ColumnStats
+	counts [][KindEmpty + 1]int
+	rows   int
+}
+
+// header sets names of the columns
+func (b *statsBuilder) header(row []string) {
+	b.grow(len(row))
+
+	for i, name := range row {
+		b.stats[i].Name = name
+	}
+}
+
+// add adds row values to the stats
+func (b *statsBuilder) add(row []string) {
+	b.grow(len(row))
+	b.rows++
+
+	for i, v := range row {
+		if v == "" {
+			continue
+		}
+
+		b.counts[i][inferColumnType([]string{v}).Kind]++
+
+		if n := utf8.RuneCountInString(v); n > b.stats[i].MaxLen {
+			b.stats[i].MaxLen = n
+		}
+	}
+}
+
+// grow adds stats of the new columns
+func (b *statsBuilder) grow(n int) {
+	for len(b.stats) < n {
+		b.stats = append(b.stats, ColumnStats{})
+		b.counts = append(b.counts, [KindEmpty + 1]int{})
+	}
+}
+
+// result returns collected stats
+func (b *statsBuilder) result() []ColumnStats {
+	for i := range b.stats {
+		counts := b.counts[i]
+
+		if counts[KindInt] > 0 && counts[KindFloat] > 0 {
+			counts[KindFloat] += counts[KindInt]
+			counts[KindInt] = 0
+		}
+
+		stats, total := &b.stats[i], 0
+		stats.Kind = KindEmpty
+
+		for kind, n := range counts {
+			total += n
+			if n > 0 && (stats.Kind == KindEmpty || n > counts[stats.Kind]) {
+				stats.Kind = ColumnKind(kind)
+			}
+		}
+
+		stats.Nulls = b.rows - total
+	}
+
+	return b.stats
+}
@@ -0,0 +1,127 @@
+package spreadsheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInferColumnType(t *testing.T) {
+	tt := []struct {
+		values []string
+		want   ColumnKind
+	}{
+		{[]string{"1", "2", "-3"}, KindInt},
+		{[]string{"1", "", "3"}, KindInt},
+		{[]string{"1", "2.5"}, KindFloat},
+		{[]string{"TRUE", "FALSE"}, KindBool},
+		{[]string{"2021-01-02", "2021-12-31"}, KindDate},
+		{[]string{"2021-01-02", "tomorrow"}, KindString},
+		{[]string{"007", "abc"}, KindString},
+		// numbers with leading zeros are identifiers
+		{[]string{"007", "010"}, KindString},
+		{[]string{"-01.5"}, KindString},
+		{[]string{"0", "0.5", "-.5", "1e5"}, KindFloat},
+		// no special and hex floats
+		{[]string{"NaN"}, KindString},
+		{[]string{"Inf"}, KindString},
+		{[]string{"0x10"}, KindString},
+		// only TRUE and FALSE are booleans
+		{[]string{"true", "false"}, KindString},
+		{[]string{"T", "F"}, KindString},
+		{[]string{"1", "0"}, KindInt},
+		{[]string{"", ""}, KindString},
+		{nil, KindString},
+	}
+
+	for _, tc := range tt {
+		if res := inferColumnType(tc.values); res.Kind != tc.want {
+			t.Errorf("inferColumnType(%q) = %v, want %v", tc.values, res.Kind, tc.want)
+		}
+	}
+}
+
+func TestInferColumnTypes(t *testing.T) {
+	rows := [][]string{
+		{"1", "a", "1.5", "TRUE", "10"},
+		{"2", "b", "2", "", "20"},
+		// rows after the first two widen mismatched columns
+		{"x", "", "", "FALSE", "30"},
+		{"", "", "", "", "2.5"},
+	}
+
+	override := []ColumnType{{}, {Kind: KindString}, {Kind: KindString}}
+	want := []ColumnKind{KindString, KindString, KindString, KindBool, KindString}
+
+	types := InferColumnTypes(rows, len(want), 2, override)
+
+	for i, w := range want {
+		if types[i].Kind != w {
+			t.Errorf("InferColumnTypes()[%d] = %v, want %v", i, types[i].Kind, w)
+		}
+	}
+}
+
+func TestCoerceRow(t *testing.T) {
+	types := []ColumnType{
+		{Kind: KindInt},
+		{Kind: KindFloat},
+		{Kind: KindBool},
+		{Kind: KindDate, Layout: "02.01.2006"},
+		{},
+	}
+
+	tt := []struct {
+		row  []string
+		want string
+		err  bool
+	}{
+		{[]string{"007", "1.50", "true", "02.01.2021", "any"}, "7,1.5,TRUE,02.01.2021,any", false},
+		{[]string{"1", "", "FALSE"}, "1,,FALSE", false},
+		{[]string{"1", "2", "true", "02.01.2021", "x", "extra"}, "1,2,TRUE,02.01.2021,x,extra", false},
+		{[]string{"1.5"}, "", true},
+		{[]string{"1", "2", "yes"}, "", true},
+		{[]string{"1", "2", "true", "2021-01-02"}, "", true},
+	}
+
+	for _, tc := range tt {
+		row := append([]string(nil), tc.row...)
+
+		err := coerceRow(row, types)
+		if (err != nil) != tc.err {
+			t.Errorf("coerceRow(%q) error = %v, want error %t", tc.row, err, tc.err)
+			continue
+		}
+
+		if res := strings.Join(row, ","); !tc.err && res != tc.want {
+			t.Errorf("coerceRow(%q) = %q, want %q", tc.row, res, tc.want)
+		}
+	}
+}
+
+func TestStatsBuilder(t *testing.T) {
+	var b statsBuilder
+
+	b.header([]string{"id", "price", "name", "note"})
+	b.add([]string{"1", "1.5", "apple"})
+	b.add([]string{"2", "2", "", ""})
+	b.add([]string{"3", "2.25", "pineapple"})
+	b.add([]string{"x"})
+
+	want := []ColumnStats{
+		{"id", KindInt, 1, 0},
+		{"price", KindFloat, 4, 1},
+		{"name", KindString, 9, 2},
+		{"note", KindEmpty, 0, 4},
+	}
+
+	res := b.result()
+	if len(res) != len(want) {
+		t.Fatalf("result() = %+v, want %+v", res, want)
+	}
+
+	for i, w := range want {
+		if res[i] != w {
+			t.Errorf("result()[%d] = %+v, want %+v", i, res[i], w)
+		}
+	}
+}
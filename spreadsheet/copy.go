@@ -1,11 +1,44 @@
 package spreadsheet
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 
 	sheets "google.golang.org/api/sheets/v4"
 )
 
+// MaxRequestCells is a number of cells that can be requested at once
+const MaxRequestCells = 10000000
+
+// ErrRangeTooLarge error returns when range contains more than
+// MaxRequestCells cells, such range may be split by Range.ChunkByCells
+var ErrRangeTooLarge error = fmt.Errorf("range too large")
+
+// ErrGridTooLarge error returns when grid of the sheet has more rows or
+// columns than Range can address, such sheet can be copied only without
+// chunks and verification, which do not need bounds of the grid
+var ErrGridTooLarge error = fmt.Errorf("grid too large")
+
+// ErrInvalidRange error returns when range is not valid, see Range.IsValid
+var ErrInvalidRange error = fmt.Errorf("invalid range")
+
+// ErrPermissionDenied error returns when API rejects request to the
+// spreadsheet with 403 Forbidden, e.g spreadsheet is not shared with
+// the service account. It wraps the original googleapi.Error.
+var ErrPermissionDenied error = fmt.Errorf("permission denied")
+
+// ErrInvalidUTF8 error returns when value contains invalid UTF-8
+// and CopyOptions.ValidateUTF8 with CopyOptions.RejectInvalidUTF8 is set
+var ErrInvalidUTF8 error = fmt.Errorf("invalid utf-8")
+
+// ErrTooManyFields error returns when row has more than
+// CopyOptions.MaxFields fields and CopyOptions.RejectWideRows is set
+var ErrTooManyFields error = fmt.Errorf("too many fields")
+
 // CSVWriter is an interface that discribes csv.Writer
 type CSVWriter interface {
 	// Error reports any error that has occurred during a previous Write or Flush.
@@ -16,42 +49,554 @@ type CSVWriter interface {
 	Write(record []string) error
 }
 
+// CaseFold is a case conversion of the copied text values
+type CaseFold int
+
+const (
+	// CaseNone leaves values as is
+	CaseNone CaseFold = iota
+	// CaseLower converts values to lower case
+	CaseLower
+	// CaseUpper converts values to upper case
+	CaseUpper
+)
+
+// CopyOptions describes options of the copy functions
+type CopyOptions struct {
+	// Context is used for API requests, context.Background is used if nil
+	Context context.Context
+
+	// Subject is an account the service reads sheets as, e.g user
+	// impersonated by service account of Workspace domain. Copy does not
+	// configure impersonation, service must already be authorized as
+	// Subject, it is only added to the errors of failed requests.
+	Subject string
+
+	// ChunkSize if positive makes copy request rows of the range by chunks
+	// of ChunkSize rows instead of requesting whole range at once.
+	// Rows are written as chunks are fetched, so only one chunk is kept
+	// in memory unless DetectHeader, PadRows or Transpose require all rows.
+	// Written rows are flushed after every chunk and copy stops on
+	// the first write error without requesting the rest of the chunks.
+	ChunkSize int
+
+	// ChunkTimeout if positive limits time of every request, so a single
+	// stuck request does not hold the whole copy until Context deadline
+	ChunkTimeout time.Duration
+
+	// Retries is a number of times request that exceeded ChunkTimeout
+	// or failed with error enabled by RateLimitBackoff or BackendBackoff
+	// is retried
+	Retries int
+
+	// RateLimitBackoff if positive enables retries of requests rejected
+	// by rate limit (429 Too Many Requests), n-th retry waits
+	// RateLimitBackoff * 2^n, n starts from zero
+	RateLimitBackoff time.Duration
+
+	// BackendBackoff is RateLimitBackoff for backend errors (500, 502, 503
+	// and 504). Under load backend intermittently fails large reads and
+	// usually needs more time to recover than the rate limit, so it is
+	// expected to be longer than RateLimitBackoff.
+	BackendBackoff time.Duration
+
+	// MaxRows limits number of copied rows, zero means no limit.
+	// Only needed rows are requested from the sheet.
+	MaxRows int
+
+	// VerifyComplete enables check that API returned all rows of the range.
+	// Under load API may silently return fewer rows than there are, so
+	// after the fetch rows after the last returned row are requested
+	// again and added to the result if there are any. Every check costs
+	// additional API request, copying whole sheet also requires request
+	// of the sheet dimensions.
+	VerifyComplete bool
+
+	// TrimEmptyRows drops rows without values at the end of the sheet,
+	// empty rows between other rows are kept
+	TrimEmptyRows bool
+
+	// PadRows pads rows with empty values to the width of the widest row.
+	// API omits empty cells at the end of the row, while empty cells
+	// between values are kept, so without padding rows may have
+	// different length.
+	PadRows bool
+
+	// Transpose writes columns of the sheet as rows, e.g for sheets that
+	// store records in columns. Rows are padded before transposition,
+	// so every written row has the same length. Options applied to the
+	// fetched rows (MaxRows, TrimEmptyRows) refer to the rows of the sheet,
+	// all others to the transposed rows.
+	Transpose bool
+
+	// NumberFormat if set formats numeric values instead of the sheet,
+	// e.g to use separators of the locale that differs from the sheet
+	// locale. Values are requested unformatted, numbers are passed to
+	// NumberFormat and booleans written as TRUE or FALSE, so number formats
+	// of the sheet (currency, percent, etc.) are lost, dates remain
+	// formatted by the sheet. With golang.org/x/text it may look like:
+	//
+	//	p := message.NewPrinter(language.German)
+	//	opts.NumberFormat = func(f float64) string {
+	//		return p.Sprint(number.Decimal(f))
+	//	}
+	NumberFormat func(float64) string
+
+	// CaseFold converts text values of the sheet to the lower or upper
+	// case. Only text values are converted, but without NumberFormat API
+	// returns every value as formatted text, so booleans (TRUE, FALSE) and
+	// numbers with letters (e.g 1E+10) are converted as well.
+	CaseFold CaseFold
+
+	// Headers if not empty are written as the first record
+	Headers []string
+
+	// DropSheetHeader drops first row of the sheet, use it together with
+	// Headers to replace sheet header
+	DropSheetHeader bool
+
+	// SuffixDuplicateHeaders makes CopyJSON suffix duplicate column names
+	// with their number (e.g name, name_2, name_3) instead of returning
+	// an error
+	SuffixDuplicateHeaders bool
+
+	// DetectHeader enables detection of the sheet header, first row of the
+	// sheet is treated as a header only if all its cells are text while
+	// some columns of the following rows contain numbers, booleans or
+	// dates. Without detection first row is always a header.
+	DetectHeader bool
+
+	// HeaderDetected if not nil receives result of the header detection
+	HeaderDetected *bool
+
+	// HeaderTransform if not nil is applied to every column name of the
+	// sheet header, e.g to make database friendly names. It is not applied
+	// to Headers and rows of the sheet without header.
+	HeaderTransform func(original string) string
+
+	// HeaderRows if greater than one is a number of the sheet rows that
+	// make up the header (e.g category over subcategory). Names of every
+	// column are joined top to bottom with HeaderSeparator into a single
+	// header record, empty names are skipped. Merged cells have value only
+	// in the first cell, so empty cells of all header rows except the
+	// last one take the name of the cell to the left. Merged header is
+	// the first row for other options, e.g DropSheetHeader drops all
+	// header rows. CopyJSON does not support it.
+	HeaderRows int
+
+	// HeaderSeparator joins names of the HeaderRows, space is used
+	// if it is empty
+	HeaderSeparator string
+
+	// UseFrozenAsHeader makes frozen rows of the sheet the header, as if
+	// HeaderRows was set to their number. Sheets without frozen rows
+	// keep HeaderRows. Frozen rows are the first rows of the sheet, so
+	// they are not used for ranges that start below the first row.
+	// It costs additional request of the sheet properties.
+	UseFrozenAsHeader bool
+
+	// ColumnTypes if not empty are types of the columns by position,
+	// values of the typed columns are parsed and written in canonical
+	// form (see ColumnType), value that does not fit the column type is
+	// an error with its row and column. Columns missing in ColumnTypes or
+	// with KindAuto are copied as is, header row is not checked.
+	// Values are parsed as formatted by the sheet, so e.g numbers with
+	// thousands separators do not fit KindInt unless NumberFormat is set.
+	ColumnTypes []ColumnType
+
+	// Stats if not nil receives stats of the copied columns, e.g to
+	// generate table definition for the result. Header row is used for
+	// column names and is not included into the stats.
+	Stats *[]ColumnStats
+
+	// PreserveText prefixes text values that look like numbers (e.g 00123)
+	// with an apostrophe, the way Sheets marks text typed as a number,
+	// so they stay text after import of the result back into Sheets.
+	// API returns only displayed values which loses this information,
+	// so values are requested second time unformatted to find such cells
+	// which costs additional API request.
+	PreserveText bool
+
+	// QuoteAll forces quoting of every field, including ones that look
+	// like numbers (e.g zip codes or ids with leading zeros), so that
+	// tools reading the result treat them as text.
+	// csv.Writer quotes only fields that require it and does not allow
+	// to change that, so CopyCSV uses its own writer when QuoteAll is set.
+	// Used only by io.Writer based copy functions.
+	QuoteAll bool
+
+	// Checksum if not nil receives hex encoded SHA-256 of the written
+	// bytes after successful copy, e.g to verify the result later.
+	// Used only by io.Writer based copy functions.
+	Checksum *string
+
+	// Delimiter is a field delimiter, comma is used if it is zero.
+	// Used only by io.Writer based copy functions.
+	Delimiter rune
+
+	// UseCRLF ends every record with \r\n instead of \n,
+	// see csv.Writer.UseCRLF.
+	// Used only by io.Writer based copy functions.
+	UseCRLF bool
+
+	// SanitizeFormulas prefixes fields starting with =, +, -, @, tab or
+	// carriage return with FormulaGuard. Spreadsheet applications evaluate
+	// such fields as formulas when the result is opened, so values
+	// written by untrusted users may run arbitrary formulas on the reader
+	// machine (CSV injection). Note that it also changes negative numbers.
+	SanitizeFormulas bool
+
+	// FormulaGuard is a prefix of sanitized fields, single quote is used
+	// if it is empty
+	FormulaGuard string
+
+	// ValidateUTF8 enables check that every field is valid UTF-8, invalid
+	// sequences are replaced with U+FFFD unless RejectInvalidUTF8 is set.
+	// Data imported into Sheets may contain invalid UTF-8 that is not
+	// accepted by strict csv readers. Check costs a pass over every field,
+	// so it is disabled by default.
+	ValidateUTF8 bool
+
+	// RejectInvalidUTF8 makes copy fail with error wrapping ErrInvalidUTF8
+	// instead of replacing invalid sequences
+	RejectInvalidUTF8 bool
+
+	// MaxFields if positive limits number of fields of the written record,
+	// fields after the limit are dropped unless RejectWideRows is set.
+	// A stray value far to the right of the data makes API return very
+	// wide rows that some csv readers are unable to handle.
+	MaxFields int
+
+	// RejectWideRows makes copy fail with error wrapping ErrTooManyFields
+	// and index of the row instead of dropping fields after MaxFields
+	RejectWideRows bool
+
+	// FlushEvery if positive makes copy flush written data every FlushEvery
+	// rows, so reader of the result (e.g HTTP client) receives data while
+	// the copy is in progress instead of all at once at the end
+	FlushEvery int
+
+	// Dedupe enables deduplication of the rows by value of the column with
+	// zero based index DedupeKeyColumn, only the first row with the value
+	// is copied or the last one if KeepLast is set. Rows without the key
+	// column have empty key. Header row of the sheet is always copied.
+	// Keys of the copied rows are kept in memory until the end of the
+	// copy, with KeepLast the kept rows themselves are kept in memory as
+	// well and written after all rows are fetched, in the order of the
+	// first row with the key.
+	Dedupe bool
+
+	// DedupeKeyColumn is zero based index of the Dedupe key column
+	DedupeKeyColumn int
+
+	// KeepLast makes Dedupe keep the last row with the key
+	KeepLast bool
+
+	// Since if not zero makes copy skip rows with value of the column
+	// with zero based index SinceColumn older than Since, e.g to export
+	// only rows changed after the previous export by "last modified"
+	// column. Values are parsed with SinceLayout in the location of Since,
+	// value that can not be parsed is an error, rows with empty value are
+	// skipped. Header row of the sheet is always copied.
+	Since time.Time
+
+	// SinceColumn is zero based index of the Since column
+	SinceColumn int
+
+	// SinceLayout is a time layout of the Since column values,
+	// DateLayout is used if empty
+	SinceLayout string
+
+	// RowFilter if not nil is called with values of every row, only rows
+	// for which it returns true are copied. Header row of the sheet is
+	// not passed to RowFilter and is always copied.
+	RowFilter func(values []interface{}) bool
+
+	// DryRun makes copy resolve the range it is about to copy and fill
+	// Estimate without requesting values and writing anything. Range of
+	// the sheet title is the sheet grid, so estimate for the whole sheet
+	// is an upper bound, grid usually has empty rows after the data.
+	DryRun bool
+
+	// Estimate if not nil receives estimate of the copy made by DryRun
+	Estimate *CopyEstimate
+}
+
+// CopyEstimate describes values that copy is about to request
+type CopyEstimate struct {
+	// Range is the resolved range of the copied values,
+	// MaxRows limit is applied
+	Range Range
+	// Rows is a number of rows in the range
+	Rows int
+	// Cells is a number of cells in the range
+	Cells int
+}
+
+// CopyCSV copies values of the sheet to w in csv format
+func CopyCSV(w io.Writer, srv *sheets.Service, id, name string, opts CopyOptions) error {
+	w, done := withChecksum(w, opts)
+
+	if err := CopyWithOptions(newCSVWriter(w, opts), srv, id, name, opts); err != nil {
+		return err
+	}
+
+	done()
+	return nil
+}
+
+// CopyTSV copies values of the sheet to w in tsv format,
+// it is the same as CopyCSV with tab as a delimiter
+func CopyTSV(w io.Writer, srv *sheets.Service, id, name string, opts CopyOptions) error {
+	opts.Delimiter = '\t'
+	return CopyCSV(w, srv, id, name, opts)
+}
+
+// CopyRange copies values of the range r to dst.
+// Before request it checks range and returns error wrapping ErrInvalidRange
+// if range is not valid, or error wrapping ErrRangeTooLarge with the number
+// of cells in the range if it contains more than MaxRequestCells cells.
+// Whole sheet and open ranges are clipped to the grid of the sheet before
+// the check, so their size is the size of the grid. Error wraps
+// ErrGridTooLarge if the grid does not fit the address space of Range.
+func CopyRange(dst CSVWriter, srv *sheets.Service, id string, r Range, opts CopyOptions) error {
+	if !r.IsValid() {
+		return fmt.Errorf("copy range: %w: %v..%v", ErrInvalidRange, r.Min, r.Max)
+	}
+
+	if r.WholeSheet || r.OpenRows || r.OpenColumns {
+		clipped, err := resolveRange(srv, id, r.apiName())
+		if err != nil {
+			return fmt.Errorf("copy range: %w", err)
+		}
+
+		if clipped.IsEmpty() {
+			return fmt.Errorf("copy range: %w: %v is outside of the sheet grid", ErrInvalidRange, r)
+		}
+
+		r = clipped
+	}
+
+	if n := r.Square(); n > MaxRequestCells {
+		return fmt.Errorf(
+			"copy range: %w: %v has %d cells, limit is %d",
+			ErrRangeTooLarge, r, n, MaxRequestCells,
+		)
+	}
+
+	return CopyWithOptions(dst, srv, id, r.String(), opts)
+}
+
 // Copy copies from src to dst until either EOF is reached on src or an error occurs.
+//
+// name is A1 notation of the values to copy. Sheet title without range
+// (e.g Sheet1) copies the whole used range of the sheet, title with range
+// (e.g Sheet1!B2:D10) copies only that region, range without title
+// (e.g B2:D10) refers to the first visible sheet. Sheet title that
+// needs quotes (e.g Sales (2024)) is quoted if it is not quoted yet.
 func Copy(dst CSVWriter, srv *sheets.Service, id, name string) error {
-	// TODO: test on big files
-	// maybe need to read by chunks
+	return CopyValues(dst, NewValuesGetter(srv), id, name, CopyOptions{})
+}
 
-	resp, err := srv.Spreadsheets.Values.Get(id, name).Do()
-	if err != nil {
-		return fmt.Errorf("copy: %v", err)
+// CopyWithOptions is like Copy but allows to configure copying with options
+func CopyWithOptions(
+	dst CSVWriter,
+	srv *sheets.Service,
+	id, name string,
+	opts CopyOptions,
+) error {
+	if opts.DryRun {
+		if err := dryRun(srv, id, name, opts); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+		return nil
+	}
+
+	if opts.UseFrozenAsHeader {
+		n, err := frozenRows(srv, id, name)
+		if err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+
+		if n > 0 {
+			opts.HeaderRows = n
+		}
+	}
+
+	// with chunks written rows are flushed after every chunk,
+	// so failed dst stops copy before next chunk is requested
+	flush := func() error {
+		dst.Flush()
+		return dst.Error()
+	}
+
+	return copyRows(dst, opts, func(fn func(int, []interface{}) error) error {
+		return visitRows(srv, id, name, opts, fn, flush)
+	})
+}
+
+// copyRows writes rows passed by visit to dst according to the options
+func copyRows(
+	dst CSVWriter,
+	opts CopyOptions,
+	visit func(fn func(rowIdx int, values []interface{}) error) error,
+) error {
+	var (
+		row     []string
+		err     error
+		stats   statsBuilder
+		written int
+	)
+
+	if len(opts.Headers) > 0 {
+		if err := dst.Write(opts.Headers); err != nil {
+			return fmt.Errorf("copy: %v", err)
+		}
+	}
+
+	if opts.DetectHeader && opts.HeaderDetected == nil {
+		opts.HeaderDetected = new(bool)
+	}
+
+	// write writes row with index i to dst
+	write := func(i int, row []string) error {
+		if opts.Stats != nil {
+			if i == 0 && opts.hasHeader() {
+				stats.header(row)
+			} else {
+				stats.add(row)
+			}
+		}
+
+		if opts.ValidateUTF8 {
+			if err := validateUTF8(row, opts.RejectInvalidUTF8); err != nil {
+				return fmt.Errorf("row %d %w", i+1, err)
+			}
+		}
+
+		if opts.SanitizeFormulas {
+			sanitizeFormulas(row, opts.FormulaGuard)
+		}
+
+		if err := dst.Write(row); err != nil {
+			return err
+		}
+
+		if written++; opts.FlushEvery > 0 && written%opts.FlushEvery == 0 {
+			dst.Flush()
+			return dst.Error()
+		}
+
+		return nil
+	}
+
+	// keptRow is a row kept by deduplication with KeepLast
+	type keptRow struct {
+		i   int
+		row []string
 	}
 
-	var row []string
+	var (
+		// keys of the copied rows, with KeepLast index of the row in kept
+		seen = make(map[string]int)
+		kept []keptRow
+	)
+
+	if opts.HeaderRows > 1 {
+		visit = mergeHeaderRows(visit, opts.HeaderRows, opts.HeaderSeparator)
+	}
+
+	err = visit(func(i int, vals []interface{}) error {
+		if i == 0 && opts.DropSheetHeader && opts.hasHeader() {
+			return nil
+		}
+
+		if !opts.keepRow(i, vals) {
+			return nil
+		}
 
-	for _, vals := range resp.Values {
 		if cap(row) == 0 {
 			// Create new slice if current is empty
 			row = make([]string, 0, len(vals))
 		}
 
 		// reset row len to reuse
-		row = row[:0]
+		row, err = appendStrings(row[:0], vals)
+		if err != nil {
+			return err
+		}
+
+		if i == 0 && opts.hasHeader() {
+			opts.transformHeader(row)
+		}
+
+		if !opts.Since.IsZero() && !(i == 0 && opts.hasHeader()) {
+			ok, err := opts.isRecent(row)
+			if err != nil {
+				return fmt.Errorf("row %d %w", i+1, err)
+			}
 
-		// loop to cast string on sheet values
-		for _, val := range vals {
-			s, ok := val.(string)
 			if !ok {
-				return fmt.Errorf("copy: unable to cast string on value %v", val)
+				return nil
 			}
+		}
 
-			row = append(row, s)
+		if opts.MaxFields > 0 && len(row) > opts.MaxFields {
+			if opts.RejectWideRows {
+				return fmt.Errorf(
+					"row %d: %w: %d fields, limit is %d",
+					i+1, ErrTooManyFields, len(row), opts.MaxFields,
+				)
+			}
+			row = row[:opts.MaxFields]
 		}
 
-		if err := dst.Write(row); err != nil {
-			return fmt.Errorf("copy: %v", err)
+		if len(opts.ColumnTypes) > 0 && !(i == 0 && opts.hasHeader()) {
+			if err := coerceRow(row, opts.ColumnTypes); err != nil {
+				return fmt.Errorf("row %d %w", i+1, err)
+			}
+		}
+
+		if !opts.Dedupe || (i == 0 && opts.hasHeader()) {
+			return write(i, row)
+		}
+
+		key := ""
+		if 0 <= opts.DedupeKeyColumn && opts.DedupeKeyColumn < len(row) {
+			key = row[opts.DedupeKeyColumn]
+		}
+
+		j, dup := seen[key]
+
+		switch {
+		case !opts.KeepLast && dup:
+			return nil
+		case !opts.KeepLast:
+			seen[key] = 0
+			return write(i, row)
+		case dup:
+			kept[j] = keptRow{i, append(kept[j].row[:0], row...)}
+		default:
+			seen[key] = len(kept)
+			kept = append(kept, keptRow{i, append([]string(nil), row...)})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("copy: %w", err)
+	}
+
+	for _, k := range kept {
+		if err := write(k.i, k.row); err != nil {
+			return fmt.Errorf("copy: %w", err)
 		}
 	}
 
+	if opts.Stats != nil {
+		*opts.Stats = stats.result()
+	}
+
 	dst.Flush()
 
 	if err := dst.Error(); err != nil {
@@ -60,3 +605,414 @@ func Copy(dst CSVWriter, srv *sheets.Service, id, name string) error {
 
 	return nil
 }
+
+// context returns context of the API requests
+func (opts CopyOptions) context() context.Context {
+	if opts.Context == nil {
+		return context.Background()
+	}
+	return opts.Context
+}
+
+// hasHeader reports if the first row of the sheet is a header
+func (opts CopyOptions) hasHeader() bool {
+	return !opts.DetectHeader || (opts.HeaderDetected != nil && *opts.HeaderDetected)
+}
+
+// transformHeader applies opts.HeaderTransform to names of the header
+func (opts CopyOptions) transformHeader(names []string) {
+	if opts.HeaderTransform == nil {
+		return
+	}
+
+	for i, name := range names {
+		names[i] = opts.HeaderTransform(name)
+	}
+}
+
+// isRecent reports if value of the row in opts.SinceColumn is not older
+// than opts.Since
+func (opts CopyOptions) isRecent(row []string) (bool, error) {
+	if opts.SinceColumn < 0 || opts.SinceColumn >= len(row) || row[opts.SinceColumn] == "" {
+		return false, nil
+	}
+
+	layout := opts.SinceLayout
+	if layout == "" {
+		layout = DateLayout
+	}
+
+	t, err := time.ParseInLocation(layout, row[opts.SinceColumn], opts.Since.Location())
+	if err != nil {
+		return false, fmt.Errorf("column %s: %w", string(colRunes(opts.SinceColumn+1)), err)
+	}
+
+	return !t.Before(opts.Since), nil
+}
+
+// keepRow reports if row with index i passes opts.RowFilter,
+// header row is always kept
+func (opts CopyOptions) keepRow(i int, vals []interface{}) bool {
+	if opts.RowFilter == nil || (i == 0 && opts.hasHeader()) {
+		return true
+	}
+	return opts.RowFilter(vals)
+}
+
+// VisitRows calls fn for every row of the sheet values with index of the
+// row starting from zero. Trailing empty cells of the row are omitted by
+// the API, so rows may have different length.
+// Visiting stops on the first error returned by fn, the error returned
+// as is.
+func VisitRows(
+	srv *sheets.Service,
+	id, name string,
+	fn func(rowIdx int, values []interface{}) error,
+) error {
+	return visitRows(srv, id, name, CopyOptions{}, fn, nil)
+}
+
+// visitRows is VisitRows that respects copy options, if flush is not nil
+// it is called after rows of every chunk are visited
+func visitRows(
+	srv *sheets.Service,
+	id, name string,
+	opts CopyOptions,
+	fn func(rowIdx int, values []interface{}) error,
+	flush func() error,
+) error {
+	fetch := func(rng string, fn func(values [][]interface{}) error) error {
+		return fetchRows(srv, id, rng, opts, fn, flush)
+	}
+
+	return visitFetched(name, opts, fetch, fn)
+}
+
+// visitFetched calls fn for every row of the values of name passed by
+// fetch to its callback, respecting copy options
+func visitFetched(
+	name string,
+	opts CopyOptions,
+	fetch func(rng string, fn func(values [][]interface{}) error) error,
+	fn func(rowIdx int, values []interface{}) error,
+) error {
+	rng := name
+	if opts.MaxRows > 0 {
+		rng = limitRows(name, opts.MaxRows)
+	}
+
+	// header detection, padding and transposition need all rows,
+	// otherwise rows are passed to fn as they are fetched
+	buffered := opts.DetectHeader || opts.PadRows || opts.Transpose
+
+	var (
+		values [][]interface{}
+		// index of the next row
+		next int
+		// empty rows that are passed to fn only if values follow them
+		empty [][]interface{}
+	)
+
+	visit := func(vals []interface{}) error {
+		i := next
+		next++
+
+		if buffered {
+			values = append(values, vals)
+			return nil
+		}
+
+		return fn(i, vals)
+	}
+
+	err := fetch(rng, func(rows [][]interface{}) error {
+		for _, vals := range rows {
+			if opts.MaxRows > 0 && next+len(empty) >= opts.MaxRows {
+				return nil
+			}
+
+			if opts.TrimEmptyRows && isEmptyRow(vals) {
+				empty = append(empty, vals)
+				continue
+			}
+
+			for _, e := range empty {
+				if err := visit(e); err != nil {
+					return err
+				}
+			}
+			empty = empty[:0]
+
+			if err := visit(vals); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !buffered {
+		return nil
+	}
+
+	if opts.Transpose {
+		values = transpose(values)
+	}
+
+	if opts.DetectHeader && opts.HeaderDetected != nil {
+		*opts.HeaderDetected = detectHeader(values)
+	}
+
+	if opts.PadRows {
+		padRows(values)
+	}
+
+	for i, vals := range values {
+		if err := fn(i, vals); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dryRun resolves range of the copy and stores its estimate
+// to opts.Estimate
+func dryRun(srv *sheets.Service, id, name string, opts CopyOptions) error {
+	rng := name
+	if opts.MaxRows > 0 {
+		rng = limitRows(name, opts.MaxRows)
+	}
+
+	r, err := resolveRange(srv, id, rng)
+	if err != nil {
+		return fmt.Errorf("dry run: %w", err)
+	}
+
+	if opts.Estimate != nil {
+		n := r.normalize()
+		*opts.Estimate = CopyEstimate{
+			Range: r,
+			Rows:  int(n.Max.Row) - int(n.Min.Row) + 1,
+			Cells: r.Square(),
+		}
+	}
+
+	return nil
+}
+
+// detectHeader checks if the first row of values looks like a header:
+// it consists of text only and there is a typed column in next rows
+func detectHeader(values [][]interface{}) bool {
+	if len(values) < 2 || len(values[0]) == 0 {
+		return false
+	}
+
+	header, err := appendStrings(nil, values[0])
+	if err != nil {
+		return false
+	}
+
+	for _, h := range header {
+		if h == "" || inferColumnType([]string{h}).Kind != KindString {
+			return false
+		}
+	}
+
+	rows := make([][]string, 0, len(values)-1)
+	for _, vals := range values[1:] {
+		row, err := appendStrings(nil, vals)
+		if err != nil {
+			return false
+		}
+		rows = append(rows, row)
+	}
+
+	for _, t := range InferColumnTypes(rows, len(header), inferRows, nil) {
+		if t.Kind != KindString {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeHeaderRows returns visit that passes first n rows of visit
+// as a single header row, see CopyOptions.HeaderRows
+func mergeHeaderRows(
+	visit func(fn func(rowIdx int, values []interface{}) error) error,
+	n int,
+	sep string,
+) func(fn func(rowIdx int, values []interface{}) error) error {
+	return func(fn func(int, []interface{}) error) error {
+		var header [][]interface{}
+
+		err := visit(func(i int, vals []interface{}) error {
+			if i >= n {
+				return fn(i-n+1, vals)
+			}
+
+			if header = append(header, vals); i < n-1 {
+				return nil
+			}
+
+			row, err := joinHeader(header, sep)
+			if err != nil {
+				return err
+			}
+
+			return fn(0, row)
+		})
+
+		// sheet has fewer rows than the header
+		if err == nil && len(header) > 0 && len(header) < n {
+			row, err := joinHeader(header, sep)
+			if err != nil {
+				return err
+			}
+
+			return fn(0, row)
+		}
+
+		return err
+	}
+}
+
+// joinHeader joins names of the header rows by columns with sep
+func joinHeader(rows [][]interface{}, sep string) ([]interface{}, error) {
+	if sep == "" {
+		sep = " "
+	}
+
+	var width int
+	for _, vals := range rows {
+		if len(vals) > width {
+			width = len(vals)
+		}
+	}
+
+	names := make([][]string, len(rows))
+	for i, vals := range rows {
+		row, err := appendStrings(make([]string, 0, width), vals)
+		if err != nil {
+			return nil, fmt.Errorf("header row %d: %v", i+1, err)
+		}
+
+		for len(row) < width {
+			row = append(row, "")
+		}
+
+		// merged cells keep the name only in the first cell
+		for j := 1; i < len(rows)-1 && j < width; j++ {
+			if row[j] == "" {
+				row[j] = row[j-1]
+			}
+		}
+
+		names[i] = row
+	}
+
+	res := make([]interface{}, width)
+	parts := make([]string, 0, len(rows))
+
+	for j := range res {
+		parts = parts[:0]
+		for _, row := range names {
+			if row[j] != "" {
+				parts = append(parts, row[j])
+			}
+		}
+		res[j] = strings.Join(parts, sep)
+	}
+
+	return res, nil
+}
+
+// transpose returns columns of the values as rows,
+// missing cells of the ragged rows are empty strings
+func transpose(values [][]interface{}) [][]interface{} {
+	var width int
+	for _, vals := range values {
+		if len(vals) > width {
+			width = len(vals)
+		}
+	}
+
+	res := make([][]interface{}, width)
+	for i := range res {
+		res[i] = make([]interface{}, len(values))
+		for j, vals := range values {
+			if i < len(vals) {
+				res[i][j] = vals[i]
+			} else {
+				res[i][j] = ""
+			}
+		}
+	}
+
+	return res
+}
+
+// padRows pads rows with empty strings to the length of the longest row
+func padRows(values [][]interface{}) {
+	var width int
+	for _, vals := range values {
+		if len(vals) > width {
+			width = len(vals)
+		}
+	}
+
+	for i, vals := range values {
+		for len(vals) < width {
+			vals = append(vals, "")
+		}
+		values[i] = vals
+	}
+}
+
+// isEmptyRow checks if row does not have any values
+func isEmptyRow(vals []interface{}) bool {
+	for _, val := range vals {
+		if s, ok := val.(string); !ok || s != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// appendStrings casts sheet values to strings and appends them to row
+func appendStrings(row []string, vals []interface{}) ([]string, error) {
+	for _, val := range vals {
+		s, ok := val.(string)
+		if !ok {
+			return row, fmt.Errorf("unable to cast string on value %v", val)
+		}
+
+		row = append(row, s)
+	}
+
+	return row, nil
+}
+
+// limitRows returns A1 notation of the first n rows of name,
+// name returned as is if it impossible to limit it
+func limitRows(name string, n int) string {
+	if r, err := NewRange(name); err == nil && r.WholeSheet {
+		return quoteSheet(r.Sheet) + "!1:" + strconv.Itoa(n)
+	} else if err == nil {
+		if rows := int(r.Max.Row) - int(r.Min.Row) + 1; rows > n {
+			r.Max.Row = r.Min.Row + uint16(n-1)
+		}
+		return r.String()
+	}
+
+	if strings.ContainsAny(name, "!:") {
+		return name
+	}
+
+	// name is a sheet title
+	return quoteSheet(sheetTitle(name)) + "!1:" + strconv.Itoa(n)
+}
@@ -0,0 +1,1399 @@
+package spreadsheet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// fakeService returns service backed by the test server which responds
+// to values requests with values for the requested range. Responses are
+// encoded once, so benchmarks measure only the client side of the copy.
+func fakeService(t testing.TB, values map[string][][]interface{}) *sheets.Service {
+	t.Helper()
+
+	responses := make(map[string][]byte, len(values))
+	for rng, vals := range values {
+		data, err := json.Marshal(&sheets.ValueRange{Range: rng, Values: vals})
+		if err != nil {
+			t.Fatalf("unable to encode values of %s: %v", rng, err)
+		}
+		responses[rng] = data
+	}
+
+	return handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, rng, ok := strings.Cut(r.URL.Path, "/values/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, ok := responses[rng]
+		if !ok {
+			http.Error(w, "unable to parse range: "+rng, http.StatusBadRequest)
+			return
+		}
+
+		w.Write(data)
+	})
+}
+
+// handlerService returns service backed by the test server which responds
+// to every request with h, opts are applied after the test server options,
+// e.g to replace its HTTP client
+func handlerService(t testing.TB, h http.HandlerFunc, opts ...option.ClientOption) *sheets.Service {
+	t.Helper()
+
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+
+	opts = append([]option.ClientOption{
+		option.WithEndpoint(ts.URL + "/"),
+		option.WithHTTPClient(ts.Client()),
+	}, opts...)
+
+	srv, err := sheets.NewService(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("unable to create service: %v", err)
+	}
+
+	return srv
+}
+
+// chunkValues returns values of rng and of its every chunk of size rows
+// for fakeService, trailing empty rows of the chunks are omitted
+// the way API does
+func chunkValues(t testing.TB, rng string, values [][]interface{}, size int) map[string][][]interface{} {
+	t.Helper()
+
+	r, err := NewRange(rng)
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	res := map[string][][]interface{}{rng: values}
+
+	for start := int(r.Min.Row); start <= int(r.Max.Row); start += size {
+		chunk := r
+		chunk.Min.Row = uint16(start)
+		if end := start + size - 1; end < int(r.Max.Row) {
+			chunk.Max.Row = uint16(end)
+		}
+
+		lo := start - int(r.Min.Row)
+		hi := lo + int(chunk.Max.Row) - int(chunk.Min.Row) + 1
+		if hi > len(values) {
+			hi = len(values)
+		}
+
+		var vals [][]interface{}
+		if lo < hi {
+			vals = values[lo:hi]
+		}
+		for len(vals) > 0 && isEmptyRow(vals[len(vals)-1]) {
+			vals = vals[:len(vals)-1]
+		}
+
+		res[chunk.String()] = vals
+	}
+
+	return res
+}
+
+func TestLimitRows(t *testing.T) {
+	tt := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{"A1:C100", 10, "A1:C10"},
+		{"B5:C7", 10, "B5:C7"},
+		{"Sheet1!A2:B50", 1, "Sheet1!A2:B2"},
+		{"Sheet1", 5, "Sheet1!1:5"},
+		{"Q1 Report", 5, "'Q1 Report'!1:5"},
+		{"Sheet1!A:C", 5, "Sheet1!A:C"},
+		{"Sheet1!", 5, "Sheet1!1:5"},
+		{"'Q1 Report'!", 5, "'Q1 Report'!1:5"},
+		{"'Q1 Report'", 5, "'Q1 Report'!1:5"},
+		{"'John''s'", 5, "'John''s'!1:5"},
+	}
+
+	for _, tc := range tt {
+		if res := limitRows(tc.name, tc.n); res != tc.want {
+			t.Errorf("limitRows(%s, %d) = %s, want %s", tc.name, tc.n, res, tc.want)
+		}
+	}
+}
+
+func TestCopyRangeTooLarge(t *testing.T) {
+	r, err := NewRange("A1:ZZ65535")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	// service is not used because range is checked before request
+	if err := CopyRange(nil, nil, "id", r, CopyOptions{}); !errors.Is(err, ErrRangeTooLarge) {
+		t.Errorf("CopyRange(%v) = %v, want %v", r, err, ErrRangeTooLarge)
+	}
+}
+
+func TestCopyRangeWholeSheet(t *testing.T) {
+	props, err := json.Marshal(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          "Sheet1",
+			GridProperties: &sheets.GridProperties{RowCount: 3, ColumnCount: 2},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode properties: %v", err)
+	}
+
+	values, err := json.Marshal(&sheets.ValueRange{Values: [][]interface{}{
+		{"name", "age"},
+		{"alice", "30"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	// values are requested only for the grid of the sheet
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, rng, ok := strings.Cut(r.URL.Path, "/values/")
+		switch {
+		case !ok:
+			w.Write(props)
+		case rng == "Sheet1!A1:B3":
+			w.Write(values)
+		default:
+			http.Error(w, "unexpected range: "+rng, http.StatusBadRequest)
+		}
+	})
+
+	for _, rng := range []string{"Sheet1!", "Sheet1!A1:B", "Sheet1!A1:3"} {
+		r, err := NewRange(rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		var b strings.Builder
+		if err := CopyRange(csv.NewWriter(&b), srv, "id", r, CopyOptions{}); err != nil {
+			t.Errorf("CopyRange(%s) error: %v", rng, err)
+			continue
+		}
+
+		if res, want := b.String(), "name,age\nalice,30\n"; res != want {
+			t.Errorf("CopyRange(%s) = %q, want %q", rng, res, want)
+		}
+	}
+
+	r, err := NewRange("Sheet1!D1:E")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if err := CopyRange(csv.NewWriter(io.Discard), srv, "id", r, CopyOptions{}); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("CopyRange(%v) = %v, want %v", r, err, ErrInvalidRange)
+	}
+}
+
+func TestCopyRangeInvalid(t *testing.T) {
+	r, err := NewRange("B2:C3")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	// moving two rows up wraps Min around the grid
+	for _, rng := range []Range{r.Move(-2, 0), {Min: r.Max, Max: r.Min}, EmptyRange} {
+		if err := CopyRange(nil, nil, "id", rng, CopyOptions{}); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("CopyRange(%v..%v) = %v, want %v", rng.Min, rng.Max, err, ErrInvalidRange)
+		}
+	}
+}
+
+// authTransport sets authorization header like oauth2 transport
+// of the service authorized as impersonated user
+type authTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(r)
+}
+
+func TestCopySubject(t *testing.T) {
+	const token = "user-token"
+
+	var requests int
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+
+		requests++
+		json.NewEncoder(w).Encode(&sheets.ValueRange{Values: [][]interface{}{{"a"}}})
+	}
+
+	const rng = "Sheet1!A1:A3"
+
+	// every chunk is a separate request that must carry authorization
+	client := &http.Client{Transport: authTransport{token, http.DefaultTransport}}
+	srv := handlerService(t, handler, option.WithHTTPClient(client))
+	opts := CopyOptions{ChunkSize: 1, Subject: "user@example.com"}
+
+	var b strings.Builder
+	if err := CopyCSV(&b, srv, "id", rng, opts); err != nil {
+		t.Fatalf("CopyCSV() error: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("CopyCSV() made %d authorized requests, want 3", requests)
+	}
+
+	if res := b.String(); res != "a\na\na\n" {
+		t.Errorf("CopyCSV() = %q, want %q", res, "a\na\na\n")
+	}
+
+	srv = handlerService(t, handler)
+
+	err := CopyCSV(io.Discard, srv, "id", rng, opts)
+	if err == nil || !strings.Contains(err.Error(), "failed reading as user@example.com") {
+		t.Errorf("CopyCSV() error = %v, want reading as subject error", err)
+	}
+
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		t.Errorf("CopyCSV() error = %v, want %d api error", err, http.StatusForbidden)
+	}
+}
+
+// fullWriter fails every write like a writer to the full disk
+type fullWriter struct {
+	writes int
+}
+
+var errDiskFull = errors.New("no space left on device")
+
+func (w *fullWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errDiskFull
+}
+
+func TestCopyChunkWriteError(t *testing.T) {
+	const rng = "Sheet1!A1:B3"
+
+	values := [][]interface{}{{"a", "1"}, {"b", "2"}, {"c", "3"}}
+	srv := fakeService(t, chunkValues(t, rng, values, 1))
+
+	var fw fullWriter
+
+	err := CopyWithOptions(csv.NewWriter(&fw), srv, "id", rng, CopyOptions{ChunkSize: 1})
+	if !errors.Is(err, errDiskFull) {
+		t.Fatalf("CopyWithOptions() error = %v, want %v", err, errDiskFull)
+	}
+
+	// copy stops after the first chunk is flushed
+	if !strings.Contains(err.Error(), "chunk Sheet1!A1:B1") {
+		t.Errorf("CopyWithOptions() error = %v, want error of the first chunk", err)
+	}
+
+	if fw.writes != 1 {
+		t.Errorf("CopyWithOptions() wrote %d times, want 1", fw.writes)
+	}
+}
+
+func TestCopyHeaderRows(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"", "Sales", "", "Costs"},
+			{"id", "Q1", "Q2", "Q1"},
+			{"1", "10", "20", "5"},
+		},
+		"Short": {
+			{"", "Sales"},
+		},
+	})
+
+	tt := []struct {
+		name string
+		opts CopyOptions
+		want string
+	}{
+		{
+			"Sheet1",
+			CopyOptions{HeaderRows: 2},
+			"id,Sales Q1,Sales Q2,Costs Q1\n1,10,20,5\n",
+		},
+		{
+			"Sheet1",
+			CopyOptions{HeaderRows: 2, HeaderSeparator: "_", HeaderTransform: strings.ToLower},
+			"id,sales_q1,sales_q2,costs_q1\n1,10,20,5\n",
+		},
+		{
+			"Sheet1",
+			CopyOptions{HeaderRows: 2, DropSheetHeader: true},
+			"1,10,20,5\n",
+		},
+		{
+			"Short",
+			CopyOptions{HeaderRows: 2},
+			",Sales\n",
+		},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		if err := CopyCSV(&b, srv, "id", tc.name, tc.opts); err != nil {
+			t.Errorf("CopyCSV(%s, %+v) error: %v", tc.name, tc.opts, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyCSV(%s, %+v) = %q, want %q", tc.name, tc.opts, res, tc.want)
+		}
+	}
+}
+
+func TestCopyPermissionDenied(t *testing.T) {
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"code": 403, "message": "The caller does not have permission"}}`, http.StatusForbidden)
+	})
+
+	copyCSV := func(opts CopyOptions) func() error {
+		return func() error {
+			return CopyCSV(io.Discard, srv, "sheet-id", "Sheet1", opts)
+		}
+	}
+
+	tt := []struct {
+		name string
+		copy func() error
+	}{
+		{"CopyCSV", copyCSV(CopyOptions{})},
+		// whole sheet with chunks requests sheet dimensions first
+		{"CopyCSV(ChunkSize)", copyCSV(CopyOptions{ChunkSize: 10})},
+		{"Copy", func() error {
+			return Copy(csv.NewWriter(io.Discard), srv, "sheet-id", "Sheet1")
+		}},
+		{"ReadAll", func() error {
+			_, err := ReadAll(srv, "sheet-id", "Sheet1")
+			return err
+		}},
+		{"CopyWithNotes", func() error {
+			return CopyWithNotes(csv.NewWriter(io.Discard), srv, "sheet-id", "Sheet1")
+		}},
+		{"CopyByMetadata", func() error {
+			return CopyByMetadata(csv.NewWriter(io.Discard), srv, "sheet-id", "key", "value")
+		}},
+	}
+
+	for _, tc := range tt {
+		err := tc.copy()
+		if !errors.Is(err, ErrPermissionDenied) || !strings.Contains(err.Error(), "sheet-id") {
+			t.Errorf("%s() error = %v, want %v with spreadsheet id", tc.name, err, ErrPermissionDenied)
+		}
+
+		var apiErr *googleapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+			t.Errorf("%s() error = %v, want wrapped %d api error", tc.name, err, http.StatusForbidden)
+		}
+	}
+}
+
+func TestCopyTranspose(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "alice", "bob"},
+			{"age", "30"},
+			{"city", "", "Paris", "extra"},
+		},
+	})
+
+	var b strings.Builder
+
+	if err := CopyCSV(&b, srv, "id", "Sheet1", CopyOptions{Transpose: true}); err != nil {
+		t.Fatalf("CopyCSV() error: %v", err)
+	}
+
+	want := "name,age,city\nalice,30,\nbob,,Paris\n,,extra\n"
+	if res := b.String(); res != want {
+		t.Errorf("CopyCSV(Transpose) = %q, want %q", res, want)
+	}
+}
+
+func TestCopyUseFrozenAsHeader(t *testing.T) {
+	values, err := json.Marshal(&sheets.ValueRange{Values: [][]interface{}{
+		{"", "Sales", ""},
+		{"id", "Q1", "Q2"},
+		{"1", "10", "20"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	props, err := json.Marshal(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          "Sheet1",
+			GridProperties: &sheets.GridProperties{RowCount: 3, ColumnCount: 3, FrozenRowCount: 2},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode properties: %v", err)
+	}
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/values/") {
+			w.Write(values)
+			return
+		}
+		w.Write(props)
+	})
+
+	tt := []struct {
+		name string
+		want string
+	}{
+		{"Sheet1", "id,Sales Q1,Sales Q2\n1,10,20\n"},
+		{"Sheet1!A1:C3", "id,Sales Q1,Sales Q2\n1,10,20\n"},
+		// range below frozen rows has no header rows
+		{"Sheet1!A2:C3", ",Sales,\nid,Q1,Q2\n1,10,20\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		if err := CopyCSV(&b, srv, "id", tc.name, CopyOptions{UseFrozenAsHeader: true}); err != nil {
+			t.Errorf("CopyCSV(%s) error: %v", tc.name, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyCSV(%s) = %q, want %q", tc.name, res, tc.want)
+		}
+	}
+}
+
+func TestCopyPreserveText(t *testing.T) {
+	formatted, err := json.Marshal(&sheets.ValueRange{Values: [][]interface{}{
+		{"007", "7", "NaN", "Inf", "0x10", "1e5", "-.5", "abc"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	// numbers are returned as numbers, text as strings
+	unformatted, err := json.Marshal(&sheets.ValueRange{Values: [][]interface{}{
+		{"007", 7, "NaN", "Inf", "0x10", "1e5", "-.5", "abc"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	var unformattedRequests int
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("valueRenderOption") != "UNFORMATTED_VALUE" {
+			w.Write(formatted)
+			return
+		}
+
+		// first unformatted request fails and is retried
+		if unformattedRequests++; unformattedRequests == 1 {
+			http.Error(w, "backend error", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(unformatted)
+	})
+
+	opts := CopyOptions{PreserveText: true, Retries: 1, BackendBackoff: time.Millisecond}
+
+	var b strings.Builder
+	if err := CopyCSV(&b, srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyCSV() error: %v", err)
+	}
+
+	want := "'007,7,NaN,Inf,0x10,'1e5,'-.5,abc\n"
+	if res := b.String(); res != want {
+		t.Errorf("CopyCSV(PreserveText) = %q, want %q", res, want)
+	}
+
+	if unformattedRequests != 2 {
+		t.Errorf("CopyCSV(PreserveText) made %d unformatted requests, want 2", unformattedRequests)
+	}
+}
+
+// stallingService returns service backed by the test server which
+// responds to values requests with a single value, requests for which
+// stall returns true wait until the client gives up. n is a number of
+// the request starting from one.
+func stallingService(t testing.TB, stall func(n int) bool) (*sheets.Service, func() int) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+
+		if stall(n) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+
+		json.NewEncoder(w).Encode(&sheets.ValueRange{Values: [][]interface{}{{"a"}}})
+	})
+
+	return srv, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestCopyChunkTimeout(t *testing.T) {
+	const rng = "Sheet1!A1:A1"
+
+	// first attempt stalls past the timeout, retry succeeds
+	srv, requests := stallingService(t, func(n int) bool { return n == 1 })
+	opts := CopyOptions{ChunkTimeout: 50 * time.Millisecond, Retries: 1}
+
+	var b strings.Builder
+	if err := CopyCSV(&b, srv, "id", rng, opts); err != nil {
+		t.Fatalf("CopyCSV() error: %v", err)
+	}
+
+	if res := b.String(); res != "a\n" || requests() != 2 {
+		t.Errorf("CopyCSV() = %q with %d requests, want %q with 2 requests", res, requests(), "a\n")
+	}
+
+	// every attempt stalls
+	srv, requests = stallingService(t, func(int) bool { return true })
+	opts.Retries = 2
+
+	err := CopyCSV(io.Discard, srv, "id", rng, opts)
+	if !errors.Is(err, context.DeadlineExceeded) || requests() != 3 {
+		t.Errorf("CopyCSV() = %v with %d requests, want %v with 3 requests",
+			err, requests(), context.DeadlineExceeded)
+	}
+
+	// cancelled copy is not retried
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv, requests = stallingService(t, func(int) bool {
+		cancel()
+		return true
+	})
+	opts.Context = ctx
+
+	err = CopyCSV(io.Discard, srv, "id", rng, opts)
+	if !errors.Is(err, context.Canceled) || requests() != 1 {
+		t.Errorf("CopyCSV() = %v with %d requests, want %v with 1 request",
+			err, requests(), context.Canceled)
+	}
+}
+
+// gridService returns service backed by the test server which responds
+// to spreadsheet requests with a single sheet with the grid of rows and
+// cols and to values requests with a single value
+func gridService(t testing.TB, title string, rows, cols int64) *sheets.Service {
+	t.Helper()
+
+	props, err := json.Marshal(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          title,
+			GridProperties: &sheets.GridProperties{RowCount: rows, ColumnCount: cols},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode properties: %v", err)
+	}
+
+	return handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/values/") {
+			w.Write(props)
+			return
+		}
+		json.NewEncoder(w).Encode(&sheets.ValueRange{Values: [][]interface{}{{"a"}}})
+	})
+}
+
+func TestResolveRangeTitle(t *testing.T) {
+	srv := gridService(t, "Q1 Report", 10, 3)
+
+	// quoted title is unquoted before lookup of the sheet
+	for _, rng := range []string{"Q1 Report", "'Q1 Report'", "'Q1 Report'!"} {
+		r, err := resolveRange(srv, "id", rng)
+		if err != nil || r.String() != "'Q1 Report'!A1:C10" {
+			t.Errorf("resolveRange(%s) = (%v, %v), want 'Q1 Report'!A1:C10", rng, r, err)
+		}
+	}
+}
+
+func TestResolveRangeGridTooLarge(t *testing.T) {
+	tt := []struct {
+		rows, cols int64
+		err        bool
+	}{
+		{maxRow + 1, maxCol + 1, false},
+		{maxRow + 2, 26, true},
+		{1000, maxCol + 2, true},
+	}
+
+	for _, tc := range tt {
+		srv := gridService(t, "Sheet1", tc.rows, tc.cols)
+
+		for _, rng := range []string{"Sheet1", "Sheet1!", "Sheet1!A1:B"} {
+			r, err := resolveRange(srv, "id", rng)
+			if tc.err != errors.Is(err, ErrGridTooLarge) || (!tc.err && err != nil) {
+				t.Errorf("resolveRange(%s) on %dx%d grid = (%v, %v), want error %t",
+					rng, tc.rows, tc.cols, r, err, tc.err)
+			}
+		}
+	}
+}
+
+func TestCopyVerifyComplete(t *testing.T) {
+	tt := []struct {
+		name   string
+		rng    string
+		values map[string][][]interface{}
+		want   string
+	}{
+		{
+			"truncated",
+			"Sheet1!A1:A5",
+			map[string][][]interface{}{
+				"Sheet1!A1:A5": {{"a"}, {"b"}},
+				"Sheet1!A3:A5": {{"c"}, {"d"}, {"e"}},
+			},
+			"a\nb\nc\nd\ne\n",
+		},
+		{
+			"complete",
+			"Sheet1!A1:A2",
+			map[string][][]interface{}{
+				"Sheet1!A1:A2": {{"a"}, {"b"}},
+			},
+			"a\nb\n",
+		},
+		{
+			// tail is fetched at most maxTailFetches times
+			"capped",
+			"Sheet1!A1:A10",
+			map[string][][]interface{}{
+				"Sheet1!A1:A10": {{"a"}},
+				"Sheet1!A2:A10": {{"b"}},
+				"Sheet1!A3:A10": {{"c"}},
+				"Sheet1!A4:A10": {{"d"}},
+				"Sheet1!A5:A10": {{"e"}},
+			},
+			"a\nb\nc\nd\n",
+		},
+	}
+
+	for _, tc := range tt {
+		srv := fakeService(t, tc.values)
+
+		// chunk covers the whole range, so the tail is verified
+		// after the chunks as well
+		for _, size := range []int{0, 10} {
+			opts := CopyOptions{VerifyComplete: true, ChunkSize: size}
+
+			var b strings.Builder
+			if err := CopyCSV(&b, srv, "id", tc.rng, opts); err != nil {
+				t.Errorf("%s: CopyCSV(ChunkSize: %d) error: %v", tc.name, size, err)
+				continue
+			}
+
+			if res := b.String(); res != tc.want {
+				t.Errorf("%s: CopyCSV(ChunkSize: %d) = %q, want %q", tc.name, size, res, tc.want)
+			}
+		}
+	}
+}
+
+func TestVisitRows(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "age", "city"},
+			{"alice", "30"},
+			{},
+			{"bob", "25", "Rome"},
+		},
+	})
+
+	var rows []string
+
+	err := VisitRows(srv, "id", "Sheet1", func(i int, vals []interface{}) error {
+		rows = append(rows, fmt.Sprint(i, vals))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("VisitRows() error: %v", err)
+	}
+
+	// rows are not padded, trailing empty cells are omitted
+	want := "0 [name age city],1 [alice 30],2 [],3 [bob 25 Rome]"
+	if res := strings.Join(rows, ","); res != want {
+		t.Errorf("VisitRows() visited %s, want %s", res, want)
+	}
+
+	stop := errors.New("stop")
+	visited := 0
+
+	err = VisitRows(srv, "id", "Sheet1", func(i int, vals []interface{}) error {
+		visited++
+		if i == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || visited != 2 {
+		t.Errorf("VisitRows() = %v after %d rows, want %v after 2 rows", err, visited, stop)
+	}
+}
+
+func TestTrimEmptyRows(t *testing.T) {
+	tt := []struct {
+		values [][]interface{}
+		want   int
+	}{
+		{nil, 0},
+		{[][]interface{}{{"a"}, {}, {"", ""}}, 1},
+		{[][]interface{}{{"a"}, {}, {"b"}, {""}}, 3},
+		{[][]interface{}{{}, {""}}, 0},
+		{[][]interface{}{{"a"}, {"", "b"}}, 2},
+	}
+
+	const rng = "Sheet1!A1:B5"
+
+	for _, tc := range tt {
+		// every row is a separate chunk, so empty rows are kept
+		// between chunks until row with values or the end of the range
+		for _, size := range []int{0, 1} {
+			srv := fakeService(t, chunkValues(t, rng, tc.values, 1))
+			opts := CopyOptions{TrimEmptyRows: true, ChunkSize: size}
+
+			var b strings.Builder
+
+			w := csv.NewWriter(&b)
+			if err := CopyWithOptions(w, srv, "id", rng, opts); err != nil {
+				t.Errorf("CopyWithOptions(%v, ChunkSize: %d) error: %v", tc.values, size, err)
+				continue
+			}
+
+			if res := strings.Count(b.String(), "\n"); res != tc.want {
+				t.Errorf("CopyWithOptions(%v, ChunkSize: %d) = %q, want %d rows", tc.values, size, b.String(), tc.want)
+			}
+		}
+	}
+}
+
+func TestCopyChunks(t *testing.T) {
+	values := [][]interface{}{
+		{"name", "age"},
+		{"alice", "30"},
+		{},
+		{},
+		{"bob", "25"},
+		{"carol"},
+	}
+
+	const (
+		rng  = "Sheet1!A1:B8"
+		want = "name,age\nalice,30\n\n\nbob,25\ncarol\n"
+	)
+
+	for _, size := range []int{0, 1, 2, 3, 10} {
+		chunk := size
+		if chunk <= 0 {
+			// only the whole range is requested
+			chunk = len(values)
+		}
+
+		srv := fakeService(t, chunkValues(t, rng, values, chunk))
+
+		var b strings.Builder
+
+		w := csv.NewWriter(&b)
+		if err := CopyWithOptions(w, srv, "id", rng, CopyOptions{ChunkSize: size}); err != nil {
+			t.Errorf("CopyWithOptions(ChunkSize: %d) error: %v", size, err)
+			continue
+		}
+
+		if res := b.String(); res != want {
+			t.Errorf("CopyWithOptions(ChunkSize: %d) = %q, want %q", size, res, want)
+		}
+	}
+}
+
+func TestFormatNumbers(t *testing.T) {
+	values := [][]interface{}{
+		{"name", "price", "sold"},
+		{"apple", 1234.5, true},
+		{"pear", float64(2), false},
+	}
+
+	formatNumbers(values, func(f float64) string {
+		return strings.Replace(strconv.FormatFloat(f, 'f', 2, 64), ".", ",", 1)
+	})
+
+	want := [][]interface{}{
+		{"name", "price", "sold"},
+		{"apple", "1234,50", "TRUE"},
+		{"pear", "2,00", "FALSE"},
+	}
+
+	for i := range want {
+		for j := range want[i] {
+			if values[i][j] != want[i][j] {
+				t.Errorf("formatNumbers()[%d][%d] = %v, want %v", i, j, values[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestFoldCase(t *testing.T) {
+	tt := []struct {
+		fold CaseFold
+		want []interface{}
+	}{
+		{CaseNone, []interface{}{"Ärger", 1.5, true}},
+		{CaseLower, []interface{}{"ärger", 1.5, true}},
+		{CaseUpper, []interface{}{"ÄRGER", 1.5, true}},
+	}
+
+	for _, tc := range tt {
+		values := [][]interface{}{{"Ärger", 1.5, true}}
+		foldCase(values, tc.fold)
+
+		for i := range tc.want {
+			if values[0][i] != tc.want[i] {
+				t.Errorf("foldCase(%d)[%d] = %v, want %v", tc.fold, i, values[0][i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestDetectHeader(t *testing.T) {
+	tt := []struct {
+		values [][]interface{}
+		want   bool
+	}{
+		{[][]interface{}{{"name", "age"}, {"John", "33"}, {"Jane", "31"}}, true},
+		{[][]interface{}{{"name", "born"}, {"John", "1990-01-02"}}, true},
+		{[][]interface{}{{"name", "city"}, {"John", "Moscow"}}, false},
+		{[][]interface{}{{"1", "age"}, {"John", "33"}}, false},
+		{[][]interface{}{{"name", ""}, {"John", "33"}}, false},
+		{[][]interface{}{{"name", "age"}}, false},
+		{nil, false},
+	}
+
+	for _, tc := range tt {
+		if res := detectHeader(tc.values); res != tc.want {
+			t.Errorf("detectHeader(%v) = %t, want %t", tc.values, res, tc.want)
+		}
+	}
+}
+
+func TestCopySheetAndRange(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "age", "city"},
+			{"alice", "30", "Paris"},
+			{"bob", "25", "Rome"},
+		},
+		"Sheet1!A2:B3": {
+			{"alice", "30"},
+			{"bob", "25"},
+		},
+	})
+
+	tt := []struct {
+		name string
+		want string
+	}{
+		{"Sheet1", "name,age,city\nalice,30,Paris\nbob,25,Rome\n"},
+		{"Sheet1!A2:B3", "alice,30\nbob,25\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		w := csv.NewWriter(&b)
+		if err := Copy(w, srv, "id", tc.name); err != nil {
+			t.Errorf("Copy(%s) error: %v", tc.name, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("Copy(%s) = %q, want %q", tc.name, res, tc.want)
+		}
+	}
+}
+
+// flushCounter is a CSVWriter that counts Flush calls
+type flushCounter struct {
+	*csv.Writer
+	flushes int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushes++
+	f.Writer.Flush()
+}
+
+func TestCopyFlushEvery(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {{"1"}, {"2"}, {"3"}, {"4"}, {"5"}},
+	})
+
+	tt := []struct {
+		every int
+		want  int
+	}{
+		{0, 1},
+		{2, 3},
+		{5, 2},
+		{10, 1},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		w := &flushCounter{Writer: csv.NewWriter(&b)}
+		if err := CopyWithOptions(w, srv, "id", "Sheet1", CopyOptions{FlushEvery: tc.every}); err != nil {
+			t.Errorf("CopyWithOptions(FlushEvery: %d) error: %v", tc.every, err)
+			continue
+		}
+
+		if w.flushes != tc.want {
+			t.Errorf("CopyWithOptions(FlushEvery: %d) flushes = %d, want %d", tc.every, w.flushes, tc.want)
+		}
+	}
+}
+
+func TestCopyInteriorEmptyCells(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"a", "", "c"},
+			{"a"},
+			{"", "b"},
+			{},
+			{"", "", "", "d"},
+		},
+	})
+
+	tt := []struct {
+		pad  bool
+		want string
+	}{
+		{false, "a,,c\na\n,b\n\n,,,d\n"},
+		{true, "a,,c,\na,,,\n,b,,\n,,,\n,,,d\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		w := csv.NewWriter(&b)
+		if err := CopyWithOptions(w, srv, "id", "Sheet1", CopyOptions{PadRows: tc.pad}); err != nil {
+			t.Errorf("CopyWithOptions(PadRows: %t) error: %v", tc.pad, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyWithOptions(PadRows: %t) = %q, want %q", tc.pad, res, tc.want)
+		}
+	}
+}
+
+func TestCopyRowFilter(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "status"},
+			{"alice", "done"},
+			{"bob", "open"},
+			{"carol", "done"},
+		},
+	})
+
+	opts := CopyOptions{
+		RowFilter: func(vals []interface{}) bool {
+			return len(vals) > 1 && vals[1] == "done"
+		},
+	}
+
+	var b strings.Builder
+
+	if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyWithOptions(RowFilter) error: %v", err)
+	}
+
+	if res, want := b.String(), "name,status\nalice,done\ncarol,done\n"; res != want {
+		t.Errorf("CopyWithOptions(RowFilter) = %q, want %q", res, want)
+	}
+}
+
+func TestCopyMaxFields(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"a", "b"},
+			{"c", "d", "", "", "stray"},
+		},
+	})
+
+	var b strings.Builder
+
+	opts := CopyOptions{MaxFields: 2}
+	if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyWithOptions(MaxFields) error: %v", err)
+	}
+
+	if res, want := b.String(), "a,b\nc,d\n"; res != want {
+		t.Errorf("CopyWithOptions(MaxFields) = %q, want %q", res, want)
+	}
+
+	opts.RejectWideRows = true
+	err := CopyWithOptions(csv.NewWriter(io.Discard), srv, "id", "Sheet1", opts)
+	if !errors.Is(err, ErrTooManyFields) || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("CopyWithOptions(RejectWideRows) = %v, want %v in row 2", err, ErrTooManyFields)
+	}
+}
+
+func TestCopyHeaderTransform(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{" First Name", "Age "},
+			{"Alice Smith", "30"},
+		},
+	})
+
+	opts := CopyOptions{
+		HeaderTransform: func(name string) string {
+			return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		},
+	}
+
+	var b strings.Builder
+
+	if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyWithOptions(HeaderTransform) error: %v", err)
+	}
+
+	if res, want := b.String(), "first_name,age\nAlice Smith,30\n"; res != want {
+		t.Errorf("CopyWithOptions(HeaderTransform) = %q, want %q", res, want)
+	}
+}
+
+func TestCopyHeaders(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"First Name", "Age"},
+			{"Alice Smith", "30"},
+		},
+	})
+
+	tt := []struct {
+		headers []string
+		drop    bool
+		want    string
+	}{
+		// sheet header is copied as data
+		{[]string{"name", "age"}, false, "name,age\nFirst Name,Age\nAlice Smith,30\n"},
+		// sheet header is replaced
+		{[]string{"name", "age"}, true, "name,age\nAlice Smith,30\n"},
+		// sheet header is dropped
+		{nil, true, "Alice Smith,30\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		opts := CopyOptions{Headers: tc.headers, DropSheetHeader: tc.drop}
+		if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+			t.Errorf("CopyWithOptions(%q, DropSheetHeader: %t) error: %v", tc.headers, tc.drop, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyWithOptions(%q, DropSheetHeader: %t) = %q, want %q", tc.headers, tc.drop, res, tc.want)
+		}
+	}
+}
+
+func TestCopyChecksum(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {{"name", "age"}, {"alice", "30"}},
+	})
+
+	var (
+		b   strings.Builder
+		sum string
+	)
+
+	if err := CopyCSV(&b, srv, "id", "Sheet1", CopyOptions{Checksum: &sum}); err != nil {
+		t.Fatalf("CopyCSV(Checksum) error: %v", err)
+	}
+
+	want := sha256.Sum256([]byte(b.String()))
+	if sum != hex.EncodeToString(want[:]) {
+		t.Errorf("CopyCSV(Checksum) = %s, want %x", sum, want)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	opts := CopyOptions{
+		RateLimitBackoff: time.Second,
+		BackendBackoff:   5 * time.Second,
+	}
+
+	tt := []struct {
+		err     error
+		attempt int
+		delay   time.Duration
+		retry   bool
+	}{
+		{&googleapi.Error{Code: http.StatusTooManyRequests}, 0, time.Second, true},
+		{&googleapi.Error{Code: http.StatusTooManyRequests}, 2, 4 * time.Second, true},
+		{&googleapi.Error{Code: http.StatusServiceUnavailable}, 0, 5 * time.Second, true},
+		{fmt.Errorf("chunk: %w", &googleapi.Error{Code: http.StatusBadGateway}), 1, 10 * time.Second, true},
+		{&googleapi.Error{Code: http.StatusBadRequest}, 0, 0, false},
+		{errors.New("network is down"), 0, 0, false},
+	}
+
+	for _, tc := range tt {
+		delay, retry := opts.retryDelay(tc.err, tc.attempt)
+		if delay != tc.delay || retry != tc.retry {
+			t.Errorf("retryDelay(%v, %d) = (%v, %t), want (%v, %t)", tc.err, tc.attempt, delay, retry, tc.delay, tc.retry)
+		}
+	}
+
+	if _, retry := (CopyOptions{}).retryDelay(&googleapi.Error{Code: http.StatusServiceUnavailable}, 0); retry {
+		t.Errorf("retryDelay() without backoff = true, want false")
+	}
+}
+
+func TestCopyDedupe(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"id", "price"},
+			{"a", "1"},
+			{"b", "2"},
+			{"a", "3"},
+			{"c", "4"},
+			{"b", "5"},
+		},
+	})
+
+	tt := []struct {
+		keepLast bool
+		want     string
+	}{
+		{false, "id,price\na,1\nb,2\nc,4\n"},
+		{true, "id,price\na,3\nb,5\nc,4\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		opts := CopyOptions{Dedupe: true, KeepLast: tc.keepLast}
+		if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+			t.Errorf("CopyWithOptions(KeepLast: %t) error: %v", tc.keepLast, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyWithOptions(KeepLast: %t) = %q, want %q", tc.keepLast, res, tc.want)
+		}
+	}
+}
+
+func TestCopySince(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "modified"},
+			{"alice", "2024-01-01 10:00"},
+			{"bob", "2024-03-01 09:30"},
+			{"carol"},
+			{"dave", "2024-02-01 00:00"},
+		},
+	})
+
+	opts := CopyOptions{
+		Since:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		SinceColumn: 1,
+		SinceLayout: "2006-01-02 15:04",
+	}
+
+	var b strings.Builder
+
+	if err := CopyWithOptions(csv.NewWriter(&b), srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyWithOptions(Since) error: %v", err)
+	}
+
+	if res, want := b.String(), "name,modified\nbob,2024-03-01 09:30\ndave,2024-02-01 00:00\n"; res != want {
+		t.Errorf("CopyWithOptions(Since) = %q, want %q", res, want)
+	}
+
+	opts.SinceLayout = ""
+	if err := CopyWithOptions(csv.NewWriter(io.Discard), srv, "id", "Sheet1", opts); err == nil {
+		t.Errorf("CopyWithOptions(Since) with wrong layout expected error")
+	}
+}
+
+func TestCopyDryRun(t *testing.T) {
+	tt := []struct {
+		name    string
+		maxRows int
+		want    CopyEstimate
+	}{
+		{"Sheet1!A1:C100", 0, CopyEstimate{Range{Max: CellAddr{2, 99}, Sheet: "Sheet1"}, 100, 300}},
+		{"Sheet1!A1:C100", 10, CopyEstimate{Range{Max: CellAddr{2, 9}, Sheet: "Sheet1"}, 10, 30}},
+		{"B2:B5", 0, CopyEstimate{Range{Min: CellAddr{1, 1}, Max: CellAddr{1, 4}}, 4, 4}},
+	}
+
+	for _, tc := range tt {
+		var (
+			b   strings.Builder
+			res CopyEstimate
+		)
+
+		opts := CopyOptions{
+			DryRun:   true,
+			Estimate: &res,
+			MaxRows:  tc.maxRows,
+			Headers:  []string{"a", "b", "c"},
+		}
+
+		// service is not used because range is resolved without requests
+		if err := CopyWithOptions(csv.NewWriter(&b), nil, "id", tc.name, opts); err != nil {
+			t.Errorf("CopyWithOptions(%s, DryRun) error: %v", tc.name, err)
+			continue
+		}
+
+		if b.Len() != 0 {
+			t.Errorf("CopyWithOptions(%s, DryRun) written %q, want nothing", tc.name, b.String())
+		}
+
+		if res != tc.want {
+			t.Errorf("CopyWithOptions(%s, DryRun) estimate = %+v, want %+v", tc.name, res, tc.want)
+		}
+	}
+}
+
+func TestCopyGridTooLarge(t *testing.T) {
+	// API returns all values of the sheet title without bounds of the grid
+	srv := gridService(t, "Sheet1", maxRow+2, 3)
+
+	var b strings.Builder
+	if err := CopyCSV(&b, srv, "id", "Sheet1", CopyOptions{}); err != nil || b.String() != "a\n" {
+		t.Errorf("CopyCSV() = (%q, %v), want %q", b.String(), err, "a\n")
+	}
+
+	// chunks and verification need bounds of the grid, rows after
+	// the last addressable one must not be silently lost
+	tt := []struct {
+		name string
+		opts CopyOptions
+	}{
+		{"ChunkSize", CopyOptions{ChunkSize: 1000}},
+		{"VerifyComplete", CopyOptions{VerifyComplete: true}},
+		{"DryRun", CopyOptions{DryRun: true}},
+	}
+
+	for _, tc := range tt {
+		if err := CopyCSV(io.Discard, srv, "id", "Sheet1", tc.opts); !errors.Is(err, ErrGridTooLarge) {
+			t.Errorf("CopyCSV(%s) = %v, want %v", tc.name, err, ErrGridTooLarge)
+		}
+	}
+
+	r, err := NewRange("Sheet1!")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if err := CopyRange(csv.NewWriter(io.Discard), srv, "id", r, CopyOptions{}); !errors.Is(err, ErrGridTooLarge) {
+		t.Errorf("CopyRange(%v) = %v, want %v", r, err, ErrGridTooLarge)
+	}
+}
+
+func BenchmarkCopy(b *testing.B) {
+	// maxRow+1 is the largest range that can be copied by chunks
+	for _, rows := range []int{1000, maxRow + 1, 100000, 1000000} {
+		values := make([][]interface{}, rows)
+		for i := range values {
+			values[i] = []interface{}{strconv.Itoa(i), "name", "city"}
+		}
+
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			srv := fakeService(b, map[string][][]interface{}{"Sheet1": values})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if err := Copy(csv.NewWriter(io.Discard), srv, "id", "Sheet1"); err != nil {
+					b.Fatalf("Copy() error: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("rows=%d/chunk=1000", rows), func(b *testing.B) {
+			if rows > maxRow+1 {
+				// chunks of the grid larger than Range can address
+				// are rejected instead of copying only part of it
+				srv := gridService(b, "Sheet1", int64(rows), 3)
+
+				err := CopyWithOptions(csv.NewWriter(io.Discard), srv, "id", "Sheet1", CopyOptions{ChunkSize: 1000})
+				if !errors.Is(err, ErrGridTooLarge) {
+					b.Fatalf("CopyWithOptions() of %d rows = %v, want %v", rows, err, ErrGridTooLarge)
+				}
+
+				b.Skipf("chunked copy of %d rows is not supported: %v", rows, err)
+			}
+
+			rng := "Sheet1!A1:C" + strconv.Itoa(rows)
+			srv := fakeService(b, chunkValues(b, rng, values, 1000))
+			opts := CopyOptions{ChunkSize: 1000}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if err := CopyWithOptions(csv.NewWriter(io.Discard), srv, "id", rng, opts); err != nil {
+					b.Fatalf("CopyWithOptions() error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package spreadsheet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// fetchRows requests values of the range rng at once or by chunks of
+// opts.ChunkSize rows and passes them to fn. With chunks only one chunk
+// is kept in memory, so memory used by copy does not depend on the size
+// of the range. If flush is not nil it is called after fn for every
+// chunk and its error stops fetching of the next chunks.
+func fetchRows(
+	srv *sheets.Service,
+	id, rng string,
+	opts CopyOptions,
+	fn func(values [][]interface{}) error,
+	flush func() error,
+) error {
+	if opts.ChunkSize <= 0 {
+		values, err := getChunk(srv, id, rng, opts)
+		if err != nil {
+			return err
+		}
+
+		if opts.VerifyComplete {
+			rest, err := fetchTail(srv, id, rng, len(values), opts)
+			if err != nil {
+				return err
+			}
+
+			values = append(values, rest...)
+		}
+
+		if opts.PreserveText {
+			if err := markText(srv, id, rng, values, opts); err != nil {
+				return err
+			}
+		}
+
+		return fn(values)
+	}
+
+	r, err := resolveRange(srv, id, rng)
+	if err != nil {
+		return err
+	}
+
+	var (
+		// number of rows passed to fn
+		fetched int
+		// number of empty rows after the last fetched value
+		empty int
+		// buffer for the chunk rows, reused between chunks
+		buf [][]interface{}
+	)
+
+	for start := int(r.Min.Row); start <= int(r.Max.Row); start += opts.ChunkSize {
+		chunk := r
+		chunk.Min.Row = uint16(start)
+		if end := start + opts.ChunkSize - 1; end < int(r.Max.Row) {
+			chunk.Max.Row = uint16(end)
+		}
+
+		vals, err := getChunk(srv, id, chunk.String(), opts)
+		if err != nil {
+			return fmt.Errorf("chunk %v: %w", chunk, err)
+		}
+
+		if len(vals) > 0 {
+			if opts.PreserveText {
+				if err := markText(srv, id, chunk.String(), vals, opts); err != nil {
+					return fmt.Errorf("chunk %v: %w", chunk, err)
+				}
+			}
+
+			// API omits trailing empty rows of the chunk,
+			// restore them if there are values after
+			buf = buf[:0]
+			for ; empty > 0; empty-- {
+				buf = append(buf, []interface{}{})
+			}
+
+			buf = append(buf, vals...)
+			fetched += len(buf)
+
+			if err := fn(buf); err != nil {
+				return err
+			}
+
+			if flush != nil {
+				if err := flush(); err != nil {
+					return fmt.Errorf("chunk %v: write: %w", chunk, err)
+				}
+			}
+		}
+
+		empty += int(chunk.Max.Row) - int(chunk.Min.Row) + 1 - len(vals)
+	}
+
+	if !opts.VerifyComplete {
+		return nil
+	}
+
+	rest, err := fetchTail(srv, id, rng, fetched, opts)
+	if err != nil || len(rest) == 0 {
+		return err
+	}
+
+	if opts.PreserveText {
+		tail := r
+		tail.Min.Row = uint16(int(r.Min.Row) + fetched)
+
+		if err := markText(srv, id, tail.String(), rest, opts); err != nil {
+			return err
+		}
+	}
+
+	return fn(rest)
+}
+
+// resolveRange returns Range of rng, if rng is a sheet title or the whole
+// sheet range it returns range of the whole sheet grid, open ranges
+// are clipped to the grid. Grid that does not fit the address space
+// of Range is an error wrapping ErrGridTooLarge.
+func resolveRange(srv *sheets.Service, id, rng string) (Range, error) {
+	r, err := NewRange(rng)
+	switch {
+	case err == nil && r.WholeSheet:
+		rng = r.Sheet
+	case err == nil && (r.OpenRows || r.OpenColumns):
+		// open range is limited by the grid of its sheet
+		rows, cols, err := gridSizeOf(srv, id, r.Sheet)
+		if err != nil {
+			return EmptyRange, err
+		}
+		return r.clip(rows, cols), nil
+	case err == nil:
+		return r, nil
+	case strings.ContainsAny(rng, "!:"):
+		return EmptyRange, fmt.Errorf("unsupported range %s: %v", rng, err)
+	}
+
+	// rng is a sheet title
+	title := sheetTitle(rng)
+
+	rows, cols, err := gridSizeOf(srv, id, title)
+	if err != nil {
+		return EmptyRange, err
+	}
+
+	return Range{
+		Max:   CellAddr{shift(0, cols-1), shiftRow(0, rows-1)},
+		Sheet: title,
+	}, nil
+}
+
+// gridSizeOf returns Dimensions of the sheet, grid larger than the address
+// space of Range is an error, otherwise range clamped to the last
+// addressable row would silently lose the rows after it
+func gridSizeOf(srv *sheets.Service, id, sheet string) (rows, cols int, err error) {
+	rows, cols, err = Dimensions(srv, id, sheet)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if rows > maxRow+1 || cols > maxCol+1 {
+		return 0, 0, fmt.Errorf(
+			"%w: sheet '%s' has %d rows and %d columns, limit is %d rows and %d columns",
+			ErrGridTooLarge, sheet, rows, cols, maxRow+1, maxCol+1,
+		)
+	}
+
+	return rows, cols, nil
+}
+
+// getChunk requests values of the range rng, see retried
+func getChunk(srv *sheets.Service, id, rng string, opts CopyOptions) ([][]interface{}, error) {
+	return retried(opts, func(ctx context.Context) ([][]interface{}, error) {
+		return getValues(ctx, srv, id, rng, opts)
+	})
+}
+
+// retried calls get until it succeeds, every attempt is limited by
+// opts.ChunkTimeout and attempts that timed out or failed with retryable
+// error are retried up to opts.Retries times
+func retried(
+	opts CopyOptions,
+	get func(ctx context.Context) ([][]interface{}, error),
+) ([][]interface{}, error) {
+	parent := opts.context()
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := parent, context.CancelFunc(func() {})
+		if opts.ChunkTimeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, opts.ChunkTimeout)
+		}
+
+		values, err := get(ctx)
+		cancel()
+
+		if err == nil {
+			return values, nil
+		}
+
+		// retry only if chunk timed out, not the whole copy
+		timedOut := errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil
+
+		delay, retryable := opts.retryDelay(err, attempt)
+		if !(timedOut || retryable) || attempt >= opts.Retries {
+			return nil, err
+		}
+
+		if err := sleep(parent, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay returns delay before retry of the attempt that failed with
+// err, false is returned if err is not retried by backoff
+func (opts CopyOptions) retryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return 0, false
+	}
+
+	var backoff time.Duration
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests:
+		backoff = opts.RateLimitBackoff
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		backoff = opts.BackendBackoff
+	}
+
+	if backoff <= 0 {
+		return 0, false
+	}
+
+	return backoff << attempt, true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// getValues requests values of the range rng with the render options
+// required by copy options
+func getValues(
+	ctx context.Context,
+	srv *sheets.Service,
+	id, rng string,
+	opts CopyOptions,
+) ([][]interface{}, error) {
+	call := srv.Spreadsheets.Values.Get(id, quoteName(rng)).Context(ctx)
+
+	if opts.NumberFormat != nil {
+		call = call.ValueRenderOption("UNFORMATTED_VALUE").
+			DateTimeRenderOption("FORMATTED_STRING")
+	}
+
+	resp, err := call.Do()
+	if err != nil {
+		return nil, opts.readError(id, err)
+	}
+
+	// fold before numbers are formatted as text
+	foldCase(resp.Values, opts.CaseFold)
+
+	if opts.NumberFormat != nil {
+		formatNumbers(resp.Values, opts.NumberFormat)
+	}
+
+	return resp.Values, nil
+}
+
+// readError returns error of the values request to the spreadsheet id,
+// see accessError and CopyOptions.Subject
+func (opts CopyOptions) readError(id string, err error) error {
+	err = accessError(id, err)
+	if opts.Subject != "" {
+		err = fmt.Errorf("failed reading as %s: %w", opts.Subject, err)
+	}
+	return err
+}
+
+// accessError wraps 403 Forbidden error of the API request to the
+// spreadsheet id with ErrPermissionDenied, other errors returned as is
+func accessError(id string, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		return fmt.Errorf("%w: spreadsheet %s: %w", ErrPermissionDenied, id, err)
+	}
+
+	return err
+}
+
+// formatNumbers replaces unformatted numbers and booleans with strings
+func formatNumbers(values [][]interface{}, format func(float64) string) {
+	for _, vals := range values {
+		for i, val := range vals {
+			switch v := val.(type) {
+			case float64:
+				vals[i] = format(v)
+			case bool:
+				vals[i] = strings.ToUpper(strconv.FormatBool(v))
+			}
+		}
+	}
+}
+
+// foldCase converts case of the text values
+func foldCase(values [][]interface{}, fold CaseFold) {
+	var conv func(string) string
+
+	switch fold {
+	case CaseLower:
+		conv = strings.ToLower
+	case CaseUpper:
+		conv = strings.ToUpper
+	default:
+		return
+	}
+
+	for _, vals := range values {
+		for i, val := range vals {
+			if s, ok := val.(string); ok {
+				vals[i] = conv(s)
+			}
+		}
+	}
+}
+
+// maxTailFetches limits number of requests made by fetchTail
+const maxTailFetches = 3
+
+// fetchTail requests rows of rng after the first fetched rows that were
+// already requested and returns them, requests are repeated until there
+// are nothing left
+func fetchTail(
+	srv *sheets.Service,
+	id, rng string,
+	fetched int,
+	opts CopyOptions,
+) ([][]interface{}, error) {
+	r, err := resolveRange(srv, id, rng)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify range: %w", err)
+	}
+
+	var values [][]interface{}
+
+	for i := 0; i < maxTailFetches; i++ {
+		next := int(r.Min.Row) + fetched + len(values)
+		if next > int(r.Max.Row) {
+			break
+		}
+
+		tail := r
+		tail.Min.Row = uint16(next)
+
+		rest, err := getChunk(srv, id, tail.String(), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rest) == 0 {
+			break
+		}
+
+		values = append(values, rest...)
+	}
+
+	return values, nil
+}
+
+// regexpDecimal matches decimal numbers as Sheets parses them,
+// unlike strconv.ParseFloat it does not match NaN, Inf or hex floats
+var regexpDecimal = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// markText prefixes values that are stored in the sheet as text but look
+// like numbers with an apostrophe
+func markText(
+	srv *sheets.Service,
+	id, rng string,
+	values [][]interface{},
+	opts CopyOptions,
+) error {
+	raws, err := retried(opts, func(ctx context.Context) ([][]interface{}, error) {
+		resp, err := srv.Spreadsheets.Values.Get(id, quoteName(rng)).
+			ValueRenderOption("UNFORMATTED_VALUE").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, opts.readError(id, err)
+		}
+		return resp.Values, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, raw := range raws {
+		if i >= len(values) {
+			break
+		}
+
+		for j, val := range raw {
+			if j >= len(values[i]) {
+				break
+			}
+
+			// unformatted numbers are returned as numbers
+			s, ok := val.(string)
+			if !ok {
+				continue
+			}
+
+			if regexpDecimal.MatchString(s) {
+				values[i][j] = "'" + s
+			}
+		}
+	}
+
+	return nil
+}
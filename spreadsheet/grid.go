@@ -0,0 +1,56 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// FromGridRange converts API GridRange to the Range on the sheet with
+// given name. GridRange indexes are zero based and half-open, missing end
+// index means that range is unbounded in that dimension, such ranges
+// are limited by the last addressable row or column.
+func FromGridRange(g *sheets.GridRange, sheet string) (Range, error) {
+	if g == nil {
+		return EmptyRange, fmt.Errorf("from grid range: grid range is nil")
+	}
+
+	minCol, lastCol, err := gridBounds(g.StartColumnIndex, g.EndColumnIndex, maxCol)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("from grid range: columns: %v", err)
+	}
+
+	minRow, lastRow, err := gridBounds(g.StartRowIndex, g.EndRowIndex, maxRow)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("from grid range: rows: %v", err)
+	}
+
+	return Range{
+		Min:   CellAddr{minCol, minRow},
+		Max:   CellAddr{lastCol, lastRow},
+		Sheet: sheet,
+	}, nil
+}
+
+// gridBounds converts half-open GridRange indexes to inclusive bounds,
+// last is the last addressable index
+func gridBounds(start, end, last int64) (uint16, uint16, error) {
+	if end == 0 {
+		// unbounded
+		end = last + 1
+	}
+
+	if start < 0 || end <= start {
+		return 0, 0, fmt.Errorf("invalid indexes [%d, %d)", start, end)
+	}
+
+	if start > last {
+		return 0, 0, fmt.Errorf("start index %d is out of bounds", start)
+	}
+
+	if end > last+1 {
+		end = last + 1
+	}
+
+	return uint16(start), uint16(end - 1), nil
+}
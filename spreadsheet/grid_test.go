@@ -0,0 +1,36 @@
+package spreadsheet
+
+import (
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestFromGridRange(t *testing.T) {
+	tt := []struct {
+		grid   *sheets.GridRange
+		result string
+		err    bool
+	}{
+		{&sheets.GridRange{EndColumnIndex: 2, EndRowIndex: 2}, "Data!A1:B2", false},
+		{
+			&sheets.GridRange{
+				StartColumnIndex: 1, EndColumnIndex: 26,
+				StartRowIndex: 4, EndRowIndex: 2303,
+			},
+			"Data!B5:Z2303",
+			false,
+		},
+		{&sheets.GridRange{StartColumnIndex: 2, EndColumnIndex: 3}, "Data!C1:C65535", false},
+		{&sheets.GridRange{StartColumnIndex: 2, EndColumnIndex: 2}, "", true},
+		{&sheets.GridRange{StartRowIndex: 100000}, "", true},
+		{nil, "", true},
+	}
+
+	for _, tc := range tt {
+		r, err := FromGridRange(tc.grid, "Data")
+		if (err != nil) != tc.err || (err == nil && r.String() != tc.result) {
+			t.Errorf("FromGridRange(%+v) = (%v, %v), want %s", tc.grid, r, err, tc.result)
+		}
+	}
+}
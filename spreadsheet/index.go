@@ -0,0 +1,105 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// SpreadsheetIndex caches mapping between titles and ids of the sheets
+// of one spreadsheet and grid dimensions of the sheets. Index is built
+// once and never changes after that, so it is safe for concurrent use.
+// Sheets added, renamed or removed after the index was built are not
+// reflected in it.
+type SpreadsheetIndex struct {
+	ids    map[string]int64
+	titles map[int64]string
+	grids  map[string]gridSize
+	// first is the title of the first sheet
+	first string
+}
+
+// gridSize is a number of rows and columns of the sheet grid
+type gridSize struct {
+	rows, cols int
+}
+
+// NewSpreadsheetIndex requests sheet properties of the spreadsheet
+// and builds index of them
+func NewSpreadsheetIndex(srv *sheets.Service, id string) (*SpreadsheetIndex, error) {
+	resp, err := srv.Spreadsheets.Get(id).Fields("sheets.properties(sheetId,title,gridProperties(rowCount,columnCount))").Do()
+	if err != nil {
+		return nil, fmt.Errorf("spreadsheet index: %w", accessError(id, err))
+	}
+
+	return newSpreadsheetIndex(resp.Sheets), nil
+}
+
+// newSpreadsheetIndex builds index of the sheets
+func newSpreadsheetIndex(list []*sheets.Sheet) *SpreadsheetIndex {
+	idx := &SpreadsheetIndex{
+		ids:    make(map[string]int64, len(list)),
+		titles: make(map[int64]string, len(list)),
+		grids:  make(map[string]gridSize, len(list)),
+	}
+
+	for _, sheet := range list {
+		if sheet.Properties == nil {
+			continue
+		}
+
+		props := sheet.Properties
+		if len(idx.ids) == 0 {
+			idx.first = props.Title
+		}
+
+		idx.ids[props.Title] = props.SheetId
+		idx.titles[props.SheetId] = props.Title
+
+		if grid := props.GridProperties; grid != nil {
+			idx.grids[props.Title] = gridSize{int(grid.RowCount), int(grid.ColumnCount)}
+		}
+	}
+
+	return idx
+}
+
+// SheetID returns id of the sheet with given title
+func (idx *SpreadsheetIndex) SheetID(name string) (int64, error) {
+	id, ok := idx.ids[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: '%s'", ErrSheetNotFound, name)
+	}
+
+	return id, nil
+}
+
+// SheetName returns title of the sheet with given id
+func (idx *SpreadsheetIndex) SheetName(id int64) (string, error) {
+	name, ok := idx.titles[id]
+	if !ok {
+		return "", fmt.Errorf("%w: id %d", ErrSheetNotFound, id)
+	}
+
+	return name, nil
+}
+
+// Dimensions returns number of rows and columns of the sheet grid
+// at the time index was built, see Dimensions.
+// Empty name means first sheet of the spreadsheet.
+func (idx *SpreadsheetIndex) Dimensions(name string) (rows, cols int, err error) {
+	if name == "" {
+		name = idx.first
+	}
+
+	if _, ok := idx.ids[name]; !ok {
+		return 0, 0, fmt.Errorf("dimensions: %w: '%s'", ErrSheetNotFound, name)
+	}
+
+	grid, ok := idx.grids[name]
+	if !ok {
+		return 0, 0, fmt.Errorf("dimensions: sheet '%s' is not a grid", name)
+	}
+
+	return grid.rows, grid.cols, nil
+}
@@ -0,0 +1,106 @@
+package spreadsheet
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestSpreadsheetIndex(t *testing.T) {
+	idx := newSpreadsheetIndex([]*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{SheetId: 0, Title: "Sheet1"}},
+		{Properties: &sheets.SheetProperties{SheetId: 42, Title: "Q1 Report"}},
+		{},
+	})
+
+	tt := []struct {
+		name string
+		id   int64
+	}{
+		{"Sheet1", 0},
+		{"Q1 Report", 42},
+	}
+
+	var wg sync.WaitGroup
+
+	for _, tc := range tt {
+		wg.Add(1)
+		go func(name string, id int64) {
+			defer wg.Done()
+
+			if res, err := idx.SheetID(name); err != nil || res != id {
+				t.Errorf("SheetID(%s) = %d, %v, want %d", name, res, err, id)
+			}
+
+			if res, err := idx.SheetName(id); err != nil || res != name {
+				t.Errorf("SheetName(%d) = %s, %v, want %s", id, res, err, name)
+			}
+		}(tc.name, tc.id)
+	}
+
+	wg.Wait()
+
+	if _, err := idx.SheetID("Sheet2"); !errors.Is(err, ErrSheetNotFound) {
+		t.Errorf("SheetID(Sheet2) = %v, want %v", err, ErrSheetNotFound)
+	}
+
+	if _, err := idx.SheetName(7); !errors.Is(err, ErrSheetNotFound) {
+		t.Errorf("SheetName(7) = %v, want %v", err, ErrSheetNotFound)
+	}
+}
+
+func TestRangeClipToIndex(t *testing.T) {
+	idx := newSpreadsheetIndex([]*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          "Sheet1",
+			GridProperties: &sheets.GridProperties{RowCount: 1000, ColumnCount: 26},
+		}},
+		{Properties: &sheets.SheetProperties{
+			SheetId:        1,
+			Title:          "Small",
+			GridProperties: &sheets.GridProperties{RowCount: 10, ColumnCount: 3},
+		}},
+		{Properties: &sheets.SheetProperties{SheetId: 2, Title: "Chart"}},
+	})
+
+	tt := []struct {
+		rng  string
+		want string
+	}{
+		{"B5:AZ2303", "B5:Z1000"},
+		{"Sheet1!A1:B2", "Sheet1!A1:B2"},
+		{"Small!B2:Z100", "Small!B2:C10"},
+		{"Small!", "Small!A1:C10"},
+		{"Small!D1:E5", EmptyRange.String()},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		res, err := r.ClipToIndex(idx)
+		if err != nil {
+			t.Errorf("Range{%s}.ClipToIndex() error: %v", tc.rng, err)
+			continue
+		}
+
+		if res.String() != tc.want {
+			t.Errorf("Range{%s}.ClipToIndex() = %v, want %s", tc.rng, res, tc.want)
+		}
+	}
+
+	for _, rng := range []string{"Chart!A1:B2", "Missing!A1:B2"} {
+		r, err := NewRange(rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		if res, err := r.ClipToIndex(idx); err == nil {
+			t.Errorf("Range{%s}.ClipToIndex() = %v, want error", rng, res)
+		}
+	}
+}
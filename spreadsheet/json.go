@@ -0,0 +1,243 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// ErrDuplicateHeaders error returns when header has duplicate column names
+var ErrDuplicateHeaders error = fmt.Errorf("duplicate headers")
+
+// CopyJSON copies values of the sheet to w as a JSON array of objects,
+// one object per row with column names as keys in the column order.
+//
+// Column names are taken from opts.Headers if set, then first row of the
+// sheet is copied as data unless opts.DropSheetHeader is set, otherwise
+// first row of the sheet is used. With opts.DetectHeader first row is used
+// only if it is detected as a header, otherwise all keys are column
+// letters. Columns without name are named by their letters (e.g C).
+// Duplicate names are an error wrapping ErrDuplicateHeaders unless
+// opts.SuffixDuplicateHeaders is set. Missing trailing cells are written
+// as empty strings.
+//
+// Rows are copied by CopyWithOptions, so other options are applied the
+// same way as by CopyCSV. Options of the csv format (Delimiter, QuoteAll
+// and UseCRLF) have no effect.
+func CopyJSON(w io.Writer, srv *sheets.Service, id, name string, opts CopyOptions) error {
+	return copyObjects(w, srv, id, name, opts, false)
+}
+
+// ndjsonFlushEvery is a number of objects CopyNDJSON writes between flushes
+const ndjsonFlushEvery = 1000
+
+// CopyNDJSON copies values of the sheet to w as newline delimited JSON:
+// one object per row on its own line with column names of the sheet
+// header as keys, the same way as CopyJSON does. Output is flushed every
+// few rows, so reader receives objects while the copy is in progress.
+func CopyNDJSON(w io.Writer, srv *sheets.Service, id, name string) error {
+	return copyObjects(w, srv, id, name, CopyOptions{FlushEvery: ndjsonFlushEvery}, true)
+}
+
+// copyObjects writes rows of the sheet to w as JSON objects, either as
+// an array or one object per line if lines is true
+func copyObjects(
+	w io.Writer,
+	srv *sheets.Service,
+	id, name string,
+	opts CopyOptions,
+	lines bool,
+) error {
+	op := "copy json"
+	if lines {
+		op = "copy ndjson"
+	}
+
+	if opts.DryRun {
+		if err := dryRun(srv, id, name, opts); err != nil {
+			return fmt.Errorf("%s: %v", op, err)
+		}
+		return nil
+	}
+
+	// result of the detection is shared with objectWriter to tell
+	// header of the sheet from the first row of data
+	if opts.DetectHeader && opts.HeaderDetected == nil {
+		opts.HeaderDetected = new(bool)
+	}
+
+	w, done := withChecksum(w, opts)
+	ow := &objectWriter{w: bufio.NewWriter(w), opts: opts, lines: lines}
+
+	if !lines {
+		ow.w.WriteByte('[')
+	}
+
+	if err := CopyWithOptions(ow, srv, id, name, opts); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !lines {
+		ow.w.WriteString("]\n")
+	}
+
+	if err := ow.w.Flush(); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	done()
+	return nil
+}
+
+// objectWriter is a CSVWriter that writes records as JSON objects,
+// first record is taken as keys of the objects if it is opts.Headers or
+// header of the sheet
+type objectWriter struct {
+	w     *bufio.Writer
+	opts  CopyOptions
+	lines bool
+	// keys of the objects, nil if there is no header
+	keys []string
+	// started is set after the first record
+	started bool
+	// count is a number of written objects
+	count int
+	err   error
+}
+
+// Write implements CSVWriter interface
+func (w *objectWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if !w.started {
+		w.started = true
+
+		if len(w.opts.Headers) > 0 || w.opts.hasHeader() {
+			w.keys, w.err = jsonKeys(record, w.opts.SuffixDuplicateHeaders)
+			return w.err
+		}
+	}
+
+	if w.count > 0 && !w.lines {
+		w.w.WriteByte(',')
+	}
+	w.count++
+
+	if w.err = writeObject(w.w, w.keys, record); w.err != nil {
+		return w.err
+	}
+
+	if w.lines {
+		w.err = w.w.WriteByte('\n')
+	}
+
+	return w.err
+}
+
+// Flush implements CSVWriter interface
+func (w *objectWriter) Flush() {
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+}
+
+// Error implements CSVWriter interface
+func (w *objectWriter) Error() error {
+	return w.err
+}
+
+// jsonKeys returns object keys for the header, empty names are replaced
+// with column letters and duplicates either suffixed with their number
+// (e.g name, name_2, name_3) or reported as an error
+func jsonKeys(header []string, suffix bool) ([]string, error) {
+	keys := make([]string, len(header))
+	seen := make(map[string]int, len(header))
+
+	var dups []string
+
+	for i, h := range header {
+		if h == "" {
+			h = string(colRunes(i + 1))
+		}
+
+		seen[h]++
+
+		if n := seen[h]; n > 1 {
+			if !suffix {
+				if n == 2 {
+					dups = append(dups, h)
+				}
+			} else {
+				// suffixed name may be taken by another column
+				key := h + "_" + strconv.Itoa(n)
+				for seen[key] > 0 {
+					n++
+					key = h + "_" + strconv.Itoa(n)
+				}
+
+				seen[h] = n
+				h = key
+				seen[h]++
+			}
+		}
+
+		keys[i] = h
+	}
+
+	if len(dups) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateHeaders, strings.Join(dups, ", "))
+	}
+
+	return keys, nil
+}
+
+// writeObject writes row as JSON object with given keys, values that
+// have no key are named by their column letters
+func writeObject(w *bufio.Writer, keys, row []string) error {
+	w.WriteByte('{')
+
+	n := len(keys)
+	if len(row) > n {
+		n = len(row)
+	}
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		key := string(colRunes(i + 1))
+		if i < len(keys) {
+			key = keys[i]
+		}
+
+		var val string
+		if i < len(row) {
+			val = row[i]
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+
+		v, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+
+		w.Write(k)
+		w.WriteByte(':')
+		w.Write(v)
+	}
+
+	// bufio.Writer keeps first error and returns it from every write
+	return w.WriteByte('}')
+}
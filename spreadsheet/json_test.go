@@ -0,0 +1,257 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestJSONKeys(t *testing.T) {
+	tt := []struct {
+		header []string
+		suffix bool
+		want   string
+		err    error
+	}{
+		{[]string{"id", "name"}, false, "id,name", nil},
+		{[]string{"id", "", "name"}, false, "id,B,name", nil},
+		{[]string{"id", "name", "name", "id", "name"}, false, "", ErrDuplicateHeaders},
+		{[]string{"id", "name", "name", "id", "name"}, true, "id,name,name_2,id_2,name_3", nil},
+		{[]string{"B", ""}, false, "", ErrDuplicateHeaders},
+		{[]string{"name_2", "name", "name"}, true, "name_2,name,name_3", nil},
+		{[]string{"name", "name", "name_2", "name"}, true, "name,name_2,name_2_2,name_3", nil},
+	}
+
+	for _, tc := range tt {
+		keys, err := jsonKeys(tc.header, tc.suffix)
+		if !errors.Is(err, tc.err) || strings.Join(keys, ",") != tc.want {
+			t.Errorf(
+				"jsonKeys(%q, %t) = (%q, %v), want (%s, %v)",
+				tc.header, tc.suffix, keys, err, tc.want, tc.err,
+			)
+		}
+	}
+
+	if _, err := jsonKeys([]string{"a", "b", "a", "b", "a"}, false); err == nil ||
+		!strings.Contains(err.Error(), "a, b") {
+		t.Errorf("jsonKeys() = %v, want error with collided names", err)
+	}
+}
+
+func TestWriteObject(t *testing.T) {
+	tt := []struct {
+		keys, row []string
+		want      string
+	}{
+		{[]string{"id", "name"}, []string{"1", `John "J"`}, `{"id":"1","name":"John \"J\""}`},
+		{[]string{"id", "name"}, []string{"1"}, `{"id":"1","name":""}`},
+		{[]string{"id"}, []string{"1", "x"}, `{"id":"1","B":"x"}`},
+		{[]string{"a"}, []string{"a"}, `{"a":"a"}`},
+	}
+
+	for _, tc := range tt {
+		var buf bytes.Buffer
+
+		w := bufio.NewWriter(&buf)
+		if err := writeObject(w, tc.keys, tc.row); err != nil {
+			t.Errorf("writeObject(%q, %q) = %v", tc.keys, tc.row, err)
+		}
+		w.Flush()
+
+		if buf.String() != tc.want {
+			t.Errorf("writeObject(%q, %q) = %s, want %s", tc.keys, tc.row, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestCopyNDJSON(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "age"},
+			{"alice", "30"},
+			{"bob"},
+		},
+	})
+
+	var buf bytes.Buffer
+
+	if err := CopyNDJSON(&buf, srv, "id", "Sheet1"); err != nil {
+		t.Fatalf("CopyNDJSON() error: %v", err)
+	}
+
+	want := `{"name":"alice","age":"30"}` + "\n" + `{"name":"bob","age":""}` + "\n"
+	if buf.String() != want {
+		t.Errorf("CopyNDJSON() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCopyJSONDetectHeader(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Header": {
+			{"name", "age"},
+			{"alice", "30"},
+		},
+		"NoHeader": {
+			{"alice", "Paris"},
+			{"bob", "Rome"},
+		},
+	})
+
+	tt := []struct {
+		name     string
+		want     string
+		detected bool
+	}{
+		{"Header", `[{"name":"alice","age":"30"}]` + "\n", true},
+		{"NoHeader", `[{"A":"alice","B":"Paris"},{"A":"bob","B":"Rome"}]` + "\n", false},
+	}
+
+	for _, tc := range tt {
+		var (
+			buf      bytes.Buffer
+			detected bool
+		)
+
+		opts := CopyOptions{DetectHeader: true, HeaderDetected: &detected}
+		if err := CopyJSON(&buf, srv, "id", tc.name, opts); err != nil {
+			t.Errorf("CopyJSON(%s) error: %v", tc.name, err)
+			continue
+		}
+
+		if buf.String() != tc.want || detected != tc.detected {
+			t.Errorf("CopyJSON(%s) = (%q, %t), want (%q, %t)",
+				tc.name, buf.String(), detected, tc.want, tc.detected)
+		}
+	}
+}
+
+func TestCopyJSONOptions(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "formula", "extra"},
+			{"alice", "=1+2", "x"},
+			{"bob", "3"},
+		},
+	})
+
+	var buf bytes.Buffer
+
+	opts := CopyOptions{MaxFields: 2, SanitizeFormulas: true}
+	if err := CopyJSON(&buf, srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyJSON() error: %v", err)
+	}
+
+	want := `[{"name":"alice","formula":"'=1+2"},{"name":"bob","formula":"3"}]` + "\n"
+	if buf.String() != want {
+		t.Errorf("CopyJSON(%+v) = %q, want %q", opts, buf.String(), want)
+	}
+}
+
+func TestCopyJSONDedupe(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"id", "name"},
+			{"1", "alice"},
+			{"2", "bob"},
+			{"1", "carol"},
+		},
+	})
+
+	tt := []struct {
+		keepLast bool
+		want     string
+	}{
+		{false, `[{"id":"1","name":"alice"},{"id":"2","name":"bob"}]` + "\n"},
+		{true, `[{"id":"1","name":"carol"},{"id":"2","name":"bob"}]` + "\n"},
+	}
+
+	for _, tc := range tt {
+		var buf bytes.Buffer
+
+		opts := CopyOptions{Dedupe: true, KeepLast: tc.keepLast}
+		if err := CopyJSON(&buf, srv, "id", "Sheet1", opts); err != nil {
+			t.Errorf("CopyJSON(%+v) error: %v", opts, err)
+			continue
+		}
+
+		if buf.String() != tc.want {
+			t.Errorf("CopyJSON(%+v) = %q, want %q", opts, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestCopyJSONSince(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "updated"},
+			{"alice", "2024-01-15"},
+			{"bob", "2024-02-03"},
+			{"carol"},
+		},
+	})
+
+	var buf bytes.Buffer
+
+	opts := CopyOptions{Since: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), SinceColumn: 1}
+	if err := CopyJSON(&buf, srv, "id", "Sheet1", opts); err != nil {
+		t.Fatalf("CopyJSON() error: %v", err)
+	}
+
+	want := `[{"name":"bob","updated":"2024-02-03"}]` + "\n"
+	if buf.String() != want {
+		t.Errorf("CopyJSON(Since 2024-02-01) = %q, want %q", buf.String(), want)
+	}
+
+	opts.SinceColumn = 0
+	if err := CopyJSON(io.Discard, srv, "id", "Sheet1", opts); err == nil {
+		t.Errorf("CopyJSON() with unparsable Since column expected error")
+	}
+}
+
+func TestCopyJSONUseFrozenAsHeader(t *testing.T) {
+	values, err := json.Marshal(&sheets.ValueRange{Values: [][]interface{}{
+		{"", "Sales", ""},
+		{"id", "Q1", "Q2"},
+		{"1", "10", "20"},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	props, err := json.Marshal(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          "Sheet1",
+			GridProperties: &sheets.GridProperties{RowCount: 3, ColumnCount: 3, FrozenRowCount: 2},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode properties: %v", err)
+	}
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/values/") {
+			w.Write(values)
+			return
+		}
+		w.Write(props)
+	})
+
+	var buf bytes.Buffer
+
+	if err := CopyJSON(&buf, srv, "id", "Sheet1", CopyOptions{UseFrozenAsHeader: true}); err != nil {
+		t.Fatalf("CopyJSON() error: %v", err)
+	}
+
+	want := `[{"id":"1","Sales Q1":"10","Sales Q2":"20"}]` + "\n"
+	if buf.String() != want {
+		t.Errorf("CopyJSON(UseFrozenAsHeader) = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,66 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// ErrMetadataNotFound error returns when spreadsheet has no ranges tagged
+// with given developer metadata
+var ErrMetadataNotFound error = fmt.Errorf("developer metadata not found")
+
+// CopyByMetadata copies values of the ranges tagged with developer metadata
+// with given key and value to dst. Values of all matched ranges are written
+// one after another in the order returned by the API. If no range is
+// tagged with the metadata error wraps ErrMetadataNotFound.
+func CopyByMetadata(dst CSVWriter, srv *sheets.Service, id, metadataKey, metadataValue string) error {
+	req := &sheets.BatchGetValuesByDataFilterRequest{
+		DataFilters: []*sheets.DataFilter{{
+			DeveloperMetadataLookup: &sheets.DeveloperMetadataLookup{
+				MetadataKey:   metadataKey,
+				MetadataValue: metadataValue,
+			},
+		}},
+	}
+
+	resp, err := srv.Spreadsheets.Values.BatchGetByDataFilter(id, req).Do()
+	if err != nil {
+		return fmt.Errorf("copy by metadata: %w", accessError(id, err))
+	}
+
+	var (
+		row   []string
+		found bool
+	)
+
+	for _, matched := range resp.ValueRanges {
+		if matched.ValueRange == nil {
+			continue
+		}
+		found = true
+
+		for _, vals := range matched.ValueRange.Values {
+			row, err = appendStrings(row[:0], vals)
+			if err != nil {
+				return fmt.Errorf("copy by metadata: %v", err)
+			}
+
+			if err := dst.Write(row); err != nil {
+				return fmt.Errorf("copy by metadata: %v", err)
+			}
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("copy by metadata: %w: %s=%s", ErrMetadataNotFound, metadataKey, metadataValue)
+	}
+
+	dst.Flush()
+
+	if err := dst.Error(); err != nil {
+		return fmt.Errorf("copy by metadata: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestCopyByMetadata(t *testing.T) {
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/values:batchGetByDataFilter") || r.Method != http.MethodPost {
+			http.Error(w, "unexpected request: "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+			return
+		}
+
+		var req sheets.BatchGetValuesByDataFilterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.DataFilters) != 1 ||
+			req.DataFilters[0].DeveloperMetadataLookup == nil {
+			http.Error(w, "expected developer metadata lookup", http.StatusBadRequest)
+			return
+		}
+
+		var resp sheets.BatchGetValuesByDataFilterResponse
+
+		lookup := req.DataFilters[0].DeveloperMetadataLookup
+		if lookup.MetadataKey == "export" && lookup.MetadataValue == "orders" {
+			resp.ValueRanges = []*sheets.MatchedValueRange{
+				{ValueRange: &sheets.ValueRange{Values: [][]interface{}{{"id", "total"}, {"1", "10"}}}},
+				{ValueRange: &sheets.ValueRange{Values: [][]interface{}{{"2", "20"}}}},
+			}
+		}
+
+		json.NewEncoder(w).Encode(&resp)
+	})
+
+	var b strings.Builder
+
+	w := csv.NewWriter(&b)
+	if err := CopyByMetadata(w, srv, "id", "export", "orders"); err != nil {
+		t.Fatalf("CopyByMetadata() error: %v", err)
+	}
+
+	if want := "id,total\n1,10\n2,20\n"; b.String() != want {
+		t.Errorf("CopyByMetadata() = %q, want %q", b.String(), want)
+	}
+
+	err := CopyByMetadata(csv.NewWriter(&b), srv, "id", "export", "refunds")
+	if !errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("CopyByMetadata(refunds) = %v, want %v", err, ErrMetadataNotFound)
+	}
+}
@@ -0,0 +1,66 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// CopyWithNotes copies values of the sheet to dst together with notes of
+// the cells, every value column is followed by a column with notes of
+// its cells, e.g values of columns A and B are written as A, A note, B,
+// B note. Cells without note have empty note column.
+//
+// Notes are not returned by the values API, so values are requested as
+// grid data of the spreadsheet which is slower than Copy and returns
+// only formatted values.
+func CopyWithNotes(dst CSVWriter, srv *sheets.Service, id, name string) error {
+	resp, err := srv.Spreadsheets.Get(id).
+		Ranges(quoteName(name)).
+		Fields("sheets.data.rowData.values(formattedValue,note)").
+		Do()
+	if err != nil {
+		return fmt.Errorf("copy with notes: %w", accessError(id, err))
+	}
+
+	var row []string
+
+	for _, sheet := range resp.Sheets {
+		for _, data := range sheet.Data {
+			for _, rd := range data.RowData {
+				row = appendNotes(row[:0], rd)
+
+				if err := dst.Write(row); err != nil {
+					return fmt.Errorf("copy with notes: %v", err)
+				}
+			}
+		}
+	}
+
+	dst.Flush()
+
+	if err := dst.Error(); err != nil {
+		return fmt.Errorf("copy with notes: %v", err)
+	}
+
+	return nil
+}
+
+// appendNotes appends formatted value and note of every cell of the row
+// data to row
+func appendNotes(row []string, rd *sheets.RowData) []string {
+	if rd == nil {
+		return row
+	}
+
+	for _, cell := range rd.Values {
+		if cell == nil {
+			row = append(row, "", "")
+			continue
+		}
+
+		row = append(row, cell.FormattedValue, cell.Note)
+	}
+
+	return row
+}
@@ -0,0 +1,73 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestAppendNotes(t *testing.T) {
+	tt := []struct {
+		rd   *sheets.RowData
+		want string
+	}{
+		{nil, ""},
+		{&sheets.RowData{}, ""},
+		{
+			&sheets.RowData{Values: []*sheets.CellData{
+				{FormattedValue: "alice", Note: "checked"},
+				{FormattedValue: "30"},
+			}},
+			"alice,checked,30,",
+		},
+		{
+			&sheets.RowData{Values: []*sheets.CellData{
+				nil,
+				{Note: "empty cell with note"},
+			}},
+			",,,empty cell with note",
+		},
+	}
+
+	for _, tc := range tt {
+		if res := strings.Join(appendNotes(nil, tc.rd), ","); res != tc.want {
+			t.Errorf("appendNotes(%+v) = %q, want %q", tc.rd, res, tc.want)
+		}
+	}
+}
+
+func TestCopyWithNotes(t *testing.T) {
+	var ranges string
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		ranges = r.URL.Query().Get("ranges")
+
+		json.NewEncoder(w).Encode(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+			{Data: []*sheets.GridData{{RowData: []*sheets.RowData{
+				{Values: []*sheets.CellData{{FormattedValue: "name"}, {FormattedValue: "age"}}},
+				{Values: []*sheets.CellData{
+					{FormattedValue: "alice", Note: "checked"},
+					{FormattedValue: "30"},
+				}},
+			}}}},
+		}})
+	})
+
+	var buf bytes.Buffer
+	if err := CopyWithNotes(csv.NewWriter(&buf), srv, "id", "Q1 Report"); err != nil {
+		t.Fatalf("CopyWithNotes() error: %v", err)
+	}
+
+	if ranges != "'Q1 Report'" {
+		t.Errorf("CopyWithNotes() requested ranges %q, want %q", ranges, "'Q1 Report'")
+	}
+
+	if want := "name,,age,\nalice,checked,30,\n"; buf.String() != want {
+		t.Errorf("CopyWithNotes() wrote %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,154 @@
+// Package parquet copies values of the sheets in parquet format. It is
+// kept apart from the spreadsheet package, so users of the other formats
+// do not depend on arrow.
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	arrowpq "github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/nk2ge5k/go-sheet-helper/spreadsheet"
+)
+
+// inferRows is a number of data rows used to infer column types
+const inferRows = 100
+
+// Copy copies values of the sheet to w in parquet format.
+//
+// First row of the sheet is used as column names, columns without name
+// are named by their letters (e.g C). Column types are taken from schema
+// by column position, types of columns missing in schema or with KindAuto
+// kind are inferred from the first data rows, see
+// spreadsheet.InferColumnTypes. Empty cells are written as nulls,
+// a value that does not match column type given in schema is an error.
+func Copy(w io.Writer, srv *sheets.Service, id, name string, schema []spreadsheet.ColumnType) error {
+	var (
+		rows  [][]string
+		width int
+	)
+
+	err := spreadsheet.VisitRows(srv, id, name, func(_ int, vals []interface{}) error {
+		row := make([]string, 0, len(vals))
+		for _, val := range vals {
+			s, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("unable to cast string on value %v", val)
+			}
+			row = append(row, s)
+		}
+
+		if len(row) > width {
+			width = len(row)
+		}
+
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("copy parquet: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return fmt.Errorf("copy parquet: sheet has no header row")
+	}
+
+	header, rows := rows[0], rows[1:]
+	types := spreadsheet.InferColumnTypes(rows, width, inferRows, schema)
+
+	fields := make([]arrow.Field, width)
+	for i, t := range types {
+		fields[i] = arrow.Field{
+			Name:     spreadsheet.IndexToColumn(i),
+			Type:     arrowType(t.Kind),
+			Nullable: true,
+		}
+
+		if i < len(header) && header[i] != "" {
+			fields[i].Name = header[i]
+		}
+	}
+
+	arrSchema := arrow.NewSchema(fields, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, arrSchema)
+	defer b.Release()
+
+	for i, row := range rows {
+		for j, t := range types {
+			if j >= len(row) || row[j] == "" {
+				b.Field(j).AppendNull()
+				continue
+			}
+
+			v, err := t.Parse(row[j])
+			if err != nil {
+				return fmt.Errorf(
+					"copy parquet: row %d column %s: %v", i+1, fields[j].Name, err,
+				)
+			}
+
+			appendValue(b.Field(j), v)
+		}
+	}
+
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	fw, err := pqarrow.NewFileWriter(
+		arrSchema, w, arrowpq.NewWriterProperties(), pqarrow.DefaultWriterProps(),
+	)
+	if err != nil {
+		return fmt.Errorf("copy parquet: %v", err)
+	}
+
+	if err := fw.Write(rec); err != nil {
+		fw.Close()
+		return fmt.Errorf("copy parquet: %v", err)
+	}
+
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("copy parquet: %v", err)
+	}
+
+	return nil
+}
+
+// arrowType returns arrow type for the column kind
+func arrowType(kind spreadsheet.ColumnKind) arrow.DataType {
+	switch kind {
+	case spreadsheet.KindInt:
+		return arrow.PrimitiveTypes.Int64
+	case spreadsheet.KindFloat:
+		return arrow.PrimitiveTypes.Float64
+	case spreadsheet.KindBool:
+		return arrow.FixedWidthTypes.Boolean
+	case spreadsheet.KindDate:
+		return arrow.FixedWidthTypes.Date32
+	}
+	return arrow.BinaryTypes.String
+}
+
+// appendValue appends value parsed by ColumnType to the builder
+// created for arrowType of the same column type
+func appendValue(b array.Builder, v interface{}) {
+	switch v := v.(type) {
+	case int64:
+		b.(*array.Int64Builder).Append(v)
+	case float64:
+		b.(*array.Float64Builder).Append(v)
+	case bool:
+		b.(*array.BooleanBuilder).Append(v)
+	case time.Time:
+		b.(*array.Date32Builder).Append(arrow.Date32FromTime(v))
+	case string:
+		b.(*array.StringBuilder).Append(v)
+	}
+}
@@ -0,0 +1,121 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	arrowpq "github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+	"google.golang.org/api/option"
+	sheets "google.golang.org/api/sheets/v4"
+
+	"github.com/nk2ge5k/go-sheet-helper/spreadsheet"
+)
+
+// valuesService returns service that responds with values to every
+// values request
+func valuesService(t *testing.T, values [][]interface{}) *sheets.Service {
+	t.Helper()
+
+	resp, err := json.Marshal(&sheets.ValueRange{Values: values})
+	if err != nil {
+		t.Fatalf("unable to encode values: %v", err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(resp)
+	}))
+	t.Cleanup(ts.Close)
+
+	srv, err := sheets.NewService(context.Background(),
+		option.WithEndpoint(ts.URL+"/"),
+		option.WithHTTPClient(ts.Client()),
+	)
+	if err != nil {
+		t.Fatalf("unable to create service: %v", err)
+	}
+
+	return srv
+}
+
+func TestCopy(t *testing.T) {
+	srv := valuesService(t, [][]interface{}{
+		{"id", "name", "score", "active", "joined", ""},
+		{"1", "alice", "1.5", "TRUE", "2024-01-02", "007"},
+		{"2", "", "2", "FALSE", "", "010"},
+	})
+
+	var buf bytes.Buffer
+
+	if err := Copy(&buf, srv, "id", "Sheet1", nil); err != nil {
+		t.Fatalf("Copy() error: %v", err)
+	}
+
+	tbl, err := pqarrow.ReadTable(
+		context.Background(),
+		bytes.NewReader(buf.Bytes()),
+		arrowpq.NewReaderProperties(memory.DefaultAllocator),
+		pqarrow.ArrowReadProperties{},
+		memory.DefaultAllocator,
+	)
+	if err != nil {
+		t.Fatalf("unable to read parquet: %v", err)
+	}
+	defer tbl.Release()
+
+	tt := []struct {
+		name   string
+		typ    arrow.DataType
+		values string
+	}{
+		{"id", arrow.PrimitiveTypes.Int64, "[1 2]"},
+		{"name", arrow.BinaryTypes.String, `["alice" (null)]`},
+		{"score", arrow.PrimitiveTypes.Float64, "[1.5 2]"},
+		{"active", arrow.FixedWidthTypes.Boolean, "[true false]"},
+		// dates are days since epoch, 19724 is 2024-01-02
+		{"joined", arrow.FixedWidthTypes.Date32, "[19724 (null)]"},
+		// leading zeros are kept in the string column
+		{"F", arrow.BinaryTypes.String, `["007" "010"]`},
+	}
+
+	if n := int(tbl.NumCols()); n != len(tt) {
+		t.Fatalf("Copy() wrote %d columns, want %d", n, len(tt))
+	}
+
+	for i, tc := range tt {
+		field := tbl.Schema().Field(i)
+		if field.Name != tc.name || !arrow.TypeEqual(field.Type, tc.typ) {
+			t.Errorf("Copy() column %d is %s %v, want %s %v", i, field.Name, field.Type, tc.name, tc.typ)
+			continue
+		}
+
+		res, err := array.Concatenate(tbl.Column(i).Data().Chunks(), memory.DefaultAllocator)
+		if err != nil {
+			t.Fatalf("unable to concatenate column %s: %v", tc.name, err)
+		}
+
+		if res.String() != tc.values {
+			t.Errorf("Copy() column %s = %s, want %s", tc.name, res, tc.values)
+		}
+		res.Release()
+	}
+}
+
+func TestCopySchemaMismatch(t *testing.T) {
+	srv := valuesService(t, [][]interface{}{
+		{"id", "name"},
+		{"1", "alice"},
+	})
+
+	schema := []spreadsheet.ColumnType{{}, {Kind: spreadsheet.KindInt}}
+	if err := Copy(&bytes.Buffer{}, srv, "id", "Sheet1", schema); err == nil {
+		t.Errorf("Copy() with int name column expected error")
+	}
+}
@@ -4,57 +4,97 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 const base int = 26
 
+const (
+	// maxCol is the zero based index of the last addressable column
+	maxCol = math.MaxUint16
+	// maxRow is the zero based index of the last addressable row, the row
+	// after it is reserved for InvalidCellAddr
+	maxRow = math.MaxUint16 - 1
+)
+
 var (
 	// RegexpSpeadsheetId is regexp for extracting spreadsheet id from url
 	RegexpSpeadsheetId *regexp.Regexp = regexp.MustCompile("spreadsheets/d/([a-zA-Z0-9-_]+)")
+	// RegexpDriveFileId is regexp for extracting file id from google drive url
+	RegexpDriveFileId *regexp.Regexp = regexp.MustCompile("file/d/([a-zA-Z0-9-_]+)")
+	// regexpId matches valid file id
+	regexpId *regexp.Regexp = regexp.MustCompile("^[a-zA-Z0-9-_]+$")
 	// ErrNotFound error represents error that returns when spreadsheet id not found
 	ErrNotFound error = fmt.Errorf("spreadsheet id not found")
+	// ErrWrongHost error returns when link is not a google docs link
+	ErrWrongHost error = fmt.Errorf("not a google docs hostname")
+	// ErrInvalidURL error returns when link is empty or unable to parse it
+	ErrInvalidURL error = fmt.Errorf("invalid url")
+
+	// InvalidCellAddr is returned instead of address on errors, so ignored
+	// error does not look like A1. Its row is the last row of uint16 which
+	// is not addressable, see maxRow.
+	InvalidCellAddr CellAddr = CellAddr{math.MaxUint16, math.MaxUint16}
+
+	// lastCellAddr is the bottom right cell of the address space
+	lastCellAddr CellAddr = CellAddr{maxCol, maxRow}
 
-	emptyCellAddr CellAddr
-	emptyRange    Range
+	// EmptyRange is a range without cells, it is returned when there is
+	// no resulting range (e.g ranges are not adjacent). It is written as
+	// an empty string.
+	EmptyRange Range = Range{Min: InvalidCellAddr}
 )
 
-// NewCellAddr returns new CellAddr from string address representation (e.g A1)
+// NewCellAddr returns new CellAddr from string address representation (e.g A1).
+// Whole string must be an address: column letters followed by row digits,
+// anything else (e.g A1B or A1.5) is an error. Absolute reference signs
+// before the column and the row (e.g $A$1) are ignored.
 func NewCellAddr(addr string) (CellAddr, error) {
-	if len(addr) < 2 {
-		return emptyCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
+	a := strings.TrimPrefix(addr, "$")
+	if len(a) < 2 {
+		return InvalidCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
 	}
 
-	var (
-		i    int
-		char rune
-	)
-
-	for i, char = range addr {
-		if !isLetter(char) {
-			break
-		}
+	// index of the first non letter rune, -1 means that there is no row
+	// and 0 means that there is no column
+	i := strings.IndexFunc(a, func(r rune) bool { return !isLetter(r) })
+	if i < 1 {
+		return InvalidCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
 	}
 
-	if i < 1 || i == len(addr) {
-		return emptyCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
+	c, r := strings.ToUpper(a[:i]), strings.TrimPrefix(a[i:], "$")
+	if r == "" {
+		return InvalidCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
 	}
 
-	c, r := strings.ToUpper(addr[:i]), addr[i:]
+	if strings.IndexFunc(r, func(r rune) bool { return !isDigit(r) }) != -1 {
+		return InvalidCellAddr, fmt.Errorf("invalid cell address '%s'", addr)
+	}
 
 	cell := CellAddr{}
 
-	res, err := strconv.ParseUint(r, 10, 16)
-	if err != nil {
-		return emptyCellAddr, err
+	// row 65536 is not addressable, otherwise the last cell would be
+	// parsed as InvalidCellAddr without an error
+	res, err := strconv.ParseUint(r, 10, 32)
+	if err != nil || res > maxRow+1 {
+		return InvalidCellAddr, fmt.Errorf(
+			"invalid cell address '%s': row is out of range", addr,
+		)
+	}
+	if res == 0 {
+		return InvalidCellAddr, fmt.Errorf(
+			"invalid cell address '%s': rows are numbered from 1", addr,
+		)
 	}
 	cell.Row = uint16(res - 1)
 
 	num, err := colNum(c)
 	if err != nil {
-		return emptyCellAddr, err
+		return InvalidCellAddr, err
 	}
 	cell.Col = num - 1
 
@@ -68,8 +108,38 @@ type CellAddr struct {
 
 // String implements fmt.Stringer interface
 func (c CellAddr) String() string {
-	col, row := int(c.Col), int(c.Row)
-	return string(colRunes(col+1)) + (strconv.Itoa(row + 1))
+	// longest address is CRXP65536
+	var buf [9]byte
+	i := len(buf)
+
+	for row := int(c.Row) + 1; row > 0; row /= 10 {
+		i--
+		buf[i] = byte('0' + row%10)
+	}
+
+	for col := int(c.Col) + 1; col > 0; col = (col - 1) / base {
+		i--
+		buf[i] = byte('A' + (col-1)%base)
+	}
+
+	return string(buf[i:])
+}
+
+// A1 returns A1 notation of the cell with zero based column and row,
+// it is a shortcut for CellAddr{col, row}.String(). It returns empty
+// string if row is after the last addressable row, such address can not
+// be parsed back.
+func A1(col, row uint16) string {
+	if row > maxRow {
+		return ""
+	}
+	return CellAddr{col, row}.String()
+}
+
+// IsValid returns false if address is InvalidCellAddr or any other
+// address with the row after the last addressable one
+func (c CellAddr) IsValid() bool {
+	return c.Row <= maxRow
 }
 
 // Equal compares addres with another and returns true if they are eqal
@@ -86,14 +156,92 @@ func (c CellAddr) GreaterThan(b CellAddr) bool {
 	return false
 }
 
-// Move moves cell
-// TODO: test
+// Key packs address into single integer that can be used as a map key,
+// column is stored in the high 16 bits and row in the low 16 bits
+func (c CellAddr) Key() uint32 {
+	return uint32(c.Col)<<16 | uint32(c.Row)
+}
+
+// Move moves cell by ver rows down and hor columns right, negative values
+// move it up and left. Note that the row delta goes first, unlike CellAddr
+// fields and OffsetBy, e.g Move(1, 2) moves C1 to E2. Use Down, Up, Left
+// and Right for readability. Moving out of the grid wraps around.
 func (c CellAddr) Move(ver, hor int) CellAddr {
-	// ???
 	row, col := int(c.Row)+ver, int(c.Col)+hor
 	return CellAddr{uint16(col), uint16(row)}
 }
 
+// OffsetBy returns cell moved by colDelta columns right and rowDelta rows
+// down, negative deltas move it left and up. Unlike Move it returns error
+// if resulting cell is out of the grid.
+func (c CellAddr) OffsetBy(colDelta, rowDelta int) (CellAddr, error) {
+	col, row := int(c.Col)+colDelta, int(c.Row)+rowDelta
+
+	if col < 0 || col > maxCol || row < 0 || row > maxRow {
+		return InvalidCellAddr, fmt.Errorf(
+			"offset by: %v moved by %d columns and %d rows is out of the grid",
+			c, colDelta, rowDelta,
+		)
+	}
+
+	return CellAddr{uint16(col), uint16(row)}, nil
+}
+
+// Down returns cell moved n rows down, clamped at the grid edge
+func (c CellAddr) Down(n int) CellAddr {
+	return CellAddr{c.Col, shiftRow(c.Row, n)}
+}
+
+// Up returns cell moved n rows up, clamped at the grid edge
+func (c CellAddr) Up(n int) CellAddr {
+	return CellAddr{c.Col, shiftRow(c.Row, -n)}
+}
+
+// Right returns cell moved n columns right, clamped at the grid edge
+func (c CellAddr) Right(n int) CellAddr {
+	return CellAddr{shift(c.Col, n), c.Row}
+}
+
+// Left returns cell moved n columns left, clamped at the grid edge
+func (c CellAddr) Left(n int) CellAddr {
+	return CellAddr{shift(c.Col, -n), c.Row}
+}
+
+// RelativeTo returns address relative to the origin, so origin itself
+// becomes A1. Origin is expected to be above and to the left of the
+// address, otherwise result wraps around the same way as Move does.
+func (c CellAddr) RelativeTo(origin CellAddr) CellAddr {
+	return CellAddr{c.Col - origin.Col, c.Row - origin.Row}
+}
+
+// Column helpers use zero based column index, the same as CellAddr.Col,
+// so column A is 0, B is 1 and so on.
+
+// ColumnToIndex returns zero based index of the column by its letters
+// (e.g A is 0 and AA is 26), letters are case insensitive
+func ColumnToIndex(name string) (int, error) {
+	if name == "" {
+		return 0, fmt.Errorf("column to index: empty column name")
+	}
+
+	num, err := colNum(name)
+	if err != nil {
+		return 0, fmt.Errorf("column to index: %v", err)
+	}
+
+	// colNum is one based, column MaxUint16+1 overflows to zero
+	return (int(num) + math.MaxUint16) % (math.MaxUint16 + 1), nil
+}
+
+// IndexToColumn returns letters of the column by its zero based index
+// (e.g 0 is A and 26 is AA), it returns empty string for negative index
+func IndexToColumn(index int) string {
+	if index < 0 {
+		return ""
+	}
+	return string(colRunes(index + 1))
+}
+
 // colRunes return runes describing excel column name
 func colRunes(col int) []rune {
 	i := digitsCount(col, base)
@@ -112,6 +260,10 @@ func colRunes(col int) []rune {
 
 // colNum decodes column name to integer (e.g B to 2)
 func colNum(name string) (uint16, error) {
+	// five letters column is out of uint16 range
+	if len(name) > 4 {
+		return 0, fmt.Errorf("col num: column '%s' is out of range", name)
+	}
 
 	num, fbase := 0, float64(base)
 
@@ -129,6 +281,11 @@ func colNum(name string) (uint16, error) {
 
 		digit := float64(len(name) - i - 1)
 		num += d * int(math.Pow(fbase, digit))
+
+		// column index has to fit uint16 after conversion to zero based
+		if num > math.MaxUint16+1 {
+			return 0, fmt.Errorf("col num: column '%s' is out of range", name)
+		}
 	}
 
 	return uint16(num), nil
@@ -159,90 +316,1317 @@ func isLetter(r rune) bool {
 	return false
 }
 
-// NewRange is a Range constructor from string
+// isDigit checks if rune is decimal digit (0-9)
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+// NewRange is a Range constructor from string, range may be prefixed
+// with sheet name (e.g Sheet1!A1:B2 or 'My sheet'!A1:B2).
+// Sheet name case is preserved while columns are case insensitive
+// (e.g Sheet1!a1:b2 is the same as Sheet1!A1:B2).
+// Sheet name followed by an empty range (e.g Sheet1!) is the whole sheet
+// range, see Range.WholeSheet.
+// End of the range may be only a column (e.g A1:B) or only a row
+// (e.g A1:2), see Range.OpenRows and Range.OpenColumns.
+// Sheet name may be prefixed with workbook file name in brackets as Excel
+// writes external references (e.g [Book1.xlsx]Sheet1!A1:B2),
+// see Range.Workbook.
 func NewRange(str string) (Range, error) {
+	sheet, str, err := splitSheet(str)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("new range: %v", err)
+	}
+
+	workbook, sheet, err := splitWorkbook(sheet)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("new range: %v", err)
+	}
+
+	if sheet != "" && str == "" {
+		return Range{Max: lastCellAddr, Sheet: sheet, Workbook: workbook, WholeSheet: true}, nil
+	}
+
 	s := strings.Split(str, ":")
 	if len(s) != 2 {
-		return emptyRange, fmt.Errorf("invalid range %s", str)
+		return EmptyRange, fmt.Errorf("invalid range %s", str)
 	}
 
 	min, err := NewCellAddr(s[0])
 	if err != nil {
-		return emptyRange, fmt.Errorf("new range: %v", err)
+		return EmptyRange, fmt.Errorf("new range: %v", err)
 	}
 
-	max, err := NewCellAddr(s[1])
-	if err != nil {
-		return emptyRange, fmt.Errorf("new range: %v", err)
+	r := Range{Min: min, Sheet: sheet, Workbook: workbook}
+
+	end := strings.TrimPrefix(s[1], "$")
+	switch {
+	case end != "" && strings.IndexFunc(end, func(c rune) bool { return !isLetter(c) }) < 0:
+		// column without row (e.g A1:B) is open to the bottom
+		col, err := colNum(strings.ToUpper(end))
+		if err != nil {
+			return EmptyRange, fmt.Errorf("new range: %v", err)
+		}
+		r.Max, r.OpenRows = CellAddr{col - 1, lastCellAddr.Row}, true
+	case end != "" && strings.IndexFunc(end, func(c rune) bool { return !isDigit(c) }) < 0:
+		// row without column (e.g A1:2) is open to the right
+		max, err := NewCellAddr("A" + end)
+		if err != nil {
+			return EmptyRange, fmt.Errorf("new range: %v", err)
+		}
+		r.Max, r.OpenColumns = CellAddr{lastCellAddr.Col, max.Row}, true
+	default:
+		r.Max, err = NewCellAddr(s[1])
+		if err != nil {
+			return EmptyRange, fmt.Errorf("new range: %v", err)
+		}
+	}
+
+	return r.normalize(), nil
+}
+
+// RangeFromAnchor returns range of width columns and height rows with
+// anchor as the top left cell, part of the range that goes out of the
+// grid is cut off. EmptyRange is returned if width or height is not
+// positive.
+func RangeFromAnchor(anchor CellAddr, width, height int) Range {
+	if width < 1 || height < 1 {
+		return EmptyRange
+	}
+
+	return Range{
+		Min: anchor,
+		Max: CellAddr{shift(anchor.Col, width-1), shiftRow(anchor.Row, height-1)},
+	}
+}
+
+// splitSheet splits optional sheet name from the range notation
+// and unquotes it. Quoted sheet name is read up to its closing quote,
+// so it may contain colons and exclamation marks (e.g 'A:B Comparison').
+func splitSheet(str string) (string, string, error) {
+	if strings.HasPrefix(str, "'") {
+		return splitQuotedSheet(str)
+	}
+
+	i := strings.LastIndex(str, "!")
+	if i < 0 {
+		return "", str, nil
+	}
+
+	// range between the first and the last ! means the other sheet,
+	// unquoted sheet name can not contain ! otherwise
+	if j := strings.Index(str, "!"); j < i {
+		if strings.Contains(str[j:i], ":") {
+			return "", "", fmt.Errorf("cross-sheet range '%s' is not supported", str)
+		}
+
+		return "", "", fmt.Errorf("unexpected '!' in '%s', quote the sheet name", str)
+	}
+
+	if i == 0 {
+		return "", "", fmt.Errorf("empty sheet name in '%s'", str)
+	}
+
+	return str[:i], str[i+1:], nil
+}
+
+// splitQuotedSheet splits single quoted sheet name from the range notation,
+// quotes inside of the name are escaped by doubling them
+func splitQuotedSheet(str string) (string, string, error) {
+	var b strings.Builder
+
+	for i := 1; i < len(str); i++ {
+		if str[i] != '\'' {
+			b.WriteByte(str[i])
+			continue
+		}
+
+		if i+1 < len(str) && str[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+
+		// closing quote must be followed by the range
+		if i+1 >= len(str) || str[i+1] != '!' {
+			return "", "", fmt.Errorf("expected '!' after sheet name in '%s'", str)
+		}
+
+		if b.Len() == 0 {
+			return "", "", fmt.Errorf("empty sheet name in '%s'", str)
+		}
+
+		if strings.Contains(str[i+2:], "!") {
+			return "", "", fmt.Errorf("cross-sheet range '%s' is not supported", str)
+		}
+
+		return b.String(), str[i+2:], nil
+	}
+
+	return "", "", fmt.Errorf("unterminated sheet name in '%s'", str)
+}
+
+// workbookExts are extensions of the Excel workbook names
+var workbookExts = map[string]bool{
+	".xlsx": true, ".xlsm": true, ".xlsb": true, ".xls": true,
+	".xltx": true, ".xltm": true,
+}
+
+// splitWorkbook splits workbook name in brackets from the sheet name
+// (e.g [Book1.xlsx]Sheet1). Name in brackets is a workbook only if it has
+// an extension of Excel workbook, sheets of Google Sheets may have titles
+// starting with brackets (e.g [Archive] Data).
+func splitWorkbook(sheet string) (string, string, error) {
+	if !strings.HasPrefix(sheet, "[") {
+		return "", sheet, nil
+	}
+
+	i := strings.Index(sheet, "]")
+	if i < 0 || !workbookExts[strings.ToLower(path.Ext(sheet[1:i]))] {
+		return "", sheet, nil
+	}
+
+	if i == len(sheet)-1 {
+		return "", "", fmt.Errorf("empty sheet name in '%s'", sheet)
+	}
+
+	return sheet[1:i], sheet[i+1:], nil
+}
+
+// quoteSheet returns sheet name for use in A1 notation, name is quoted
+// only if needed
+func quoteSheet(name string) string {
+	if !needsQuote(name) {
+		return name
+	}
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// sheetTitle returns title of the sheet name that may be quoted the way
+// quoteSheet quotes it (e.g 'Q1 Report' is Q1 Report), name that can not
+// be a sheet title is returned as is
+func sheetTitle(name string) string {
+	if r, err := NewRange(name + "!"); err == nil {
+		return r.Sheet
+	}
+	return name
+}
+
+// quoteName returns A1 notation name with the sheet name quoted if it
+// needs quotes and it is not quoted yet, e.g Sales (2024) becomes
+// 'Sales (2024)' and Sales (2024)!A:C becomes 'Sales (2024)'!A:C.
+// Name without ! that is a cell (e.g A1) or contains : is a range and is
+// returned as is, sheet titles that look like cells or contain colons have
+// to be quoted by the caller. Workbook of the Excel reference is dropped.
+func quoteName(name string) string {
+	if r, err := NewRange(name); err == nil {
+		return r.apiName()
+	}
+
+	if _, err := NewCellAddr(name); err == nil {
+		return name
+	}
+
+	if strings.HasPrefix(name, "'") {
+		return name
+	}
+
+	if i := strings.LastIndex(name, "!"); i > 0 {
+		return quoteSheet(name[:i]) + name[i:]
 	}
 
-	if min.GreaterThan(max) {
-		min, max = max, min
+	if strings.Contains(name, ":") {
+		return name
 	}
 
-	return Range{min, max}, nil
+	// name is a sheet title
+	return quoteSheet(name)
 }
 
-// Range represents excel range (e.g A1:B223)
-// TODO: add optional sheet name
+// needsQuote checks if sheet name has to be quoted in A1 notation, that is
+// if it contains anything except letters, digits and underscores or starts
+// with a digit. Names that look like a cell address (e.g A1) are quoted as
+// well since otherwise they are ambiguous.
+func needsQuote(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		if unicode.IsDigit(r) && i == 0 {
+			return true
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return true
+		}
+	}
+
+	_, err := NewCellAddr(name)
+	return err == nil
+}
+
+// Range represents excel range (e.g A1:B223) with optional sheet name
 type Range struct {
 	Min, Max CellAddr
+	// Sheet is the name of the sheet range belongs to,
+	// empty name means first visible sheet
+	Sheet string
+	// Workbook is the name of the Excel workbook of the external reference
+	// (e.g Book1.xlsx of [Book1.xlsx]Sheet1!A1:B2). Sheets have no such
+	// references, name is only kept to write the range back as it was
+	// and is not sent to the API.
+	Workbook string
+	// WholeSheet means that range refers to the whole sheet without bounds
+	// (e.g Sheet1!), Min and Max of such range cover every address.
+	// Range is written as the whole sheet only while it has a sheet name
+	// and is not moved or resized.
+	WholeSheet bool
+	// OpenRows means that range has no last row (e.g A1:B),
+	// Max.Row of such range is the last row of the address space.
+	// Range is written without the last row only while Max.Row is not
+	// changed, the same way as WholeSheet.
+	OpenRows bool
+	// OpenColumns means that range has no last column (e.g A1:2),
+	// see OpenRows
+	OpenColumns bool
+}
+
+// isWholeSheet checks if range is still the whole sheet range
+func (r Range) isWholeSheet() bool {
+	return r.WholeSheet && r.Sheet != "" &&
+		r.Min.Equal(CellAddr{}) && r.Max.Equal(lastCellAddr)
+}
+
+// normalize returns range with Min in the top left corner
+// and Max in the bottom right corner
+func (r Range) normalize() Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	if r.Min.Col > r.Max.Col {
+		r.Min.Col, r.Max.Col = r.Max.Col, r.Min.Col
+	}
+
+	if r.Min.Row > r.Max.Row {
+		r.Min.Row, r.Max.Row = r.Max.Row, r.Min.Row
+	}
+
+	return r
+}
+
+// IsEmpty returns true if range is the EmptyRange, sheet name is ignored
+func (r Range) IsEmpty() bool {
+	return r.Min.Equal(EmptyRange.Min) && r.Max.Equal(EmptyRange.Max)
+}
+
+// IsValid returns false if Min is to the right of or below Max, such
+// range is not produced by NewRange but may be built as a struct literal
+// or returned by Move that wrapped around the grid. EmptyRange is not
+// valid either.
+func (r Range) IsValid() bool {
+	return r.Min.Col <= r.Max.Col && r.Min.Row <= r.Max.Row
+}
+
+// IsSingleCell returns true if range consists of a single cell,
+// unlike Square() == 1 it does not compute area of the range
+func (r Range) IsSingleCell() bool {
+	return r.Min.Equal(r.Max)
+}
+
+// IsSingleRow returns true if all cells of the range are in the same row
+func (r Range) IsSingleRow() bool {
+	return r.Min.Row == r.Max.Row
+}
+
+// IsSingleColumn returns true if all cells of the range are in the same column
+func (r Range) IsSingleColumn() bool {
+	return r.Min.Col == r.Max.Col
 }
 
 // String implements fmt.Stringer interface
 func (r Range) String() string {
-	min, max := r.Min, r.Max
+	if r.IsEmpty() {
+		return ""
+	}
 
-	if min.GreaterThan(max) {
-		min, max = max, min
+	if r.isWholeSheet() {
+		return r.sheetPrefix() + "!"
 	}
 
-	return fmt.Sprintf("%v:%v", min, max)
+	r = r.normalize()
+	min, max := r.Min.String(), r.Max.String()
+
+	switch {
+	case r.OpenRows && r.Max.Row == lastCellAddr.Row:
+		max = string(colRunes(int(r.Max.Col) + 1))
+	case r.OpenColumns && r.Max.Col == lastCellAddr.Col:
+		max = strconv.Itoa(int(r.Max.Row) + 1)
+	}
+
+	if r.Sheet != "" {
+		return r.sheetPrefix() + "!" + min + ":" + max
+	}
+
+	return min + ":" + max
+}
+
+// apiName returns A1 notation of the range for API requests,
+// it is String without the workbook of the Excel reference
+func (r Range) apiName() string {
+	r.Workbook = ""
+	return r.String()
 }
 
-// Square calculates square of range
+// sheetPrefix returns quoted sheet name prefixed with the workbook name
+func (r Range) sheetPrefix() string {
+	if r.Workbook == "" {
+		return quoteSheet(r.Sheet)
+	}
+
+	name := "[" + r.Workbook + "]" + r.Sheet
+
+	// brackets keep workbook name unambiguous, so only characters
+	// other than letters, digits, underscores and dots of the
+	// extension require quotes
+	special := strings.IndexFunc(r.Workbook, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.'
+	})
+
+	if special < 0 && !needsQuote(r.Sheet) {
+		return name
+	}
+
+	return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+}
+
+// WithSheet returns copy of the range that refers to the sheet with given name
+func (r Range) WithSheet(name string) Range {
+	r.Sheet = name
+	return r
+}
+
+// WithoutSheet returns copy of the range without sheet name
+func (r Range) WithoutSheet() Range {
+	return r.WithSheet("")
+}
+
+// Square calculates square of range. Range may have up to 2^32 cells
+// which overflows int on 32-bit platforms, use Area64 there.
 func (r Range) Square() int {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	r = r.normalize()
 	min, max := r.Min, r.Max
 
-	if min.GreaterThan(max) {
-		min, max = max, min
+	w := int(max.Col) - int(min.Col) + 1
+	h := int(max.Row) - int(min.Row) + 1
+
+	return w * h
+}
+
+// Area64 is Square computed in int64, so it does not overflow on any
+// platform
+func (r Range) Area64() int64 {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	r = r.normalize()
+
+	w := int64(r.Max.Col) - int64(r.Min.Col) + 1
+	h := int64(r.Max.Row) - int64(r.Min.Row) + 1
+
+	return w * h
+}
+
+// Width returns number of columns of the range
+func (r Range) Width() int {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	r = r.normalize()
+	return int(r.Max.Col) - int(r.Min.Col) + 1
+}
+
+// Height returns number of rows of the range
+func (r Range) Height() int {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	r = r.normalize()
+	return int(r.Max.Row) - int(r.Min.Row) + 1
+}
+
+// Describe returns range with its size for logs,
+// e.g B5:Z2303 (25 cols × 2299 rows, 57,475 cells)
+func (r Range) Describe() string {
+	if r.IsEmpty() {
+		return "empty range"
+	}
+
+	return fmt.Sprintf(
+		"%v (%d cols × %d rows, %s cells)",
+		r, r.Width(), r.Height(), groupThousands(r.Area64()),
+	)
+}
+
+// groupThousands formats non-negative n with comma separated
+// groups of thousands
+func groupThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if len(s) <= 3 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + (len(s)-1)/3)
+
+	head := len(s) % 3
+	if head == 0 {
+		head = 3
+	}
+	b.WriteString(s[:head])
+
+	for i := head; i < len(s); i += 3 {
+		b.WriteByte(',')
+		b.WriteString(s[i : i+3])
+	}
+
+	return b.String()
+}
+
+// Density returns share of non-empty cells of the range r in values
+// fetched for it, from 0 for the range without values to 1 for the fully
+// populated range. Values outside of the range are not counted.
+// Low density means that it may be cheaper to request several smaller
+// ranges instead of the bounding one.
+func Density(r Range, values [][]interface{}) float64 {
+	n := r.Square()
+	if n == 0 {
+		return 0
+	}
+
+	r = r.normalize()
+	w := int(r.Max.Col) - int(r.Min.Col) + 1
+	h := int(r.Max.Row) - int(r.Min.Row) + 1
+
+	var used int
+	for i, vals := range values {
+		if i >= h {
+			break
+		}
+
+		for j, val := range vals {
+			if j >= w {
+				break
+			}
+
+			if s, ok := val.(string); val != nil && (!ok || s != "") {
+				used++
+			}
+		}
+	}
+
+	return float64(used) / float64(n)
+}
+
+// Densify returns values fetched for the range r as a grid of Height
+// rows and Width columns, so grid[row][col] is the value of the cell at
+// the offset from the top left corner. Rows and cells omitted by the API
+// are nil, values outside of the range are dropped. Whole sheet range
+// covers every address, clip it to the sheet grid first.
+func Densify(r Range, values [][]interface{}) [][]interface{} {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	w, h := r.Width(), r.Height()
+
+	grid := make([][]interface{}, h)
+	for i := range grid {
+		grid[i] = make([]interface{}, w)
+		if i < len(values) {
+			copy(grid[i], values[i])
+		}
+	}
+
+	return grid
+}
+
+// Cells returns all cells of the range row by row
+func (r Range) Cells() []CellAddr {
+	r = r.normalize()
+	return cells(r.Min, r.Max, r.Square())
+}
+
+// Perimeter returns cells of the outer ring of the range clockwise
+// starting from the top left cell: top row left to right, right column
+// down, bottom row right to left and left column up. Every cell is
+// returned once, so range of a single row or column returns all its
+// cells in the Cells order.
+func (r Range) Perimeter() []CellAddr {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	r = r.normalize()
+	min, max := r.Min, r.Max
+
+	if min.Row == max.Row || min.Col == max.Col {
+		return cells(min, max, r.Square())
+	}
+
+	w, h := r.Width(), r.Height()
+	res := make([]CellAddr, 0, 2*(w+h)-4)
+
+	for col := int(min.Col); col <= int(max.Col); col++ {
+		res = append(res, CellAddr{uint16(col), min.Row})
+	}
+	for row := int(min.Row) + 1; row <= int(max.Row); row++ {
+		res = append(res, CellAddr{max.Col, uint16(row)})
+	}
+	for col := int(max.Col) - 1; col >= int(min.Col); col-- {
+		res = append(res, CellAddr{uint16(col), max.Row})
+	}
+	for row := int(max.Row) - 1; row > int(min.Row); row-- {
+		res = append(res, CellAddr{min.Col, uint16(row)})
+	}
+
+	return res
+}
+
+// ColumnLabels returns letters of the range columns from left to right
+// (e.g A, B, C for A1:C10), e.g to use them as headers of the range
+// without header
+func (r Range) ColumnLabels() []string {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	r = r.normalize()
+
+	res := make([]string, 0, int(r.Max.Col)-int(r.Min.Col)+1)
+	for col := int(r.Min.Col); col <= int(r.Max.Col); col++ {
+		res = append(res, string(colRunes(col+1)))
+	}
+
+	return res
+}
+
+// CellAt returns address of the cell with given index in the range cells
+// listed row by row, index is in range from 0 to Square()-1
+func (r Range) CellAt(index int) (CellAddr, error) {
+	n := r.Square()
+	if index < 0 || index >= n {
+		return InvalidCellAddr, fmt.Errorf("cell at: index %d is out of range [0, %d)", index, n)
+	}
+
+	r = r.normalize()
+	w := int(r.Max.Col) - int(r.Min.Col) + 1
+
+	return CellAddr{
+		Col: r.Min.Col + uint16(index%w),
+		Row: r.Min.Row + uint16(index/w),
+	}, nil
+}
+
+// Contains returns true if cell is inside the range
+func (r Range) Contains(c CellAddr) bool {
+	r = r.normalize()
+	return r.Min.Col <= c.Col && c.Col <= r.Max.Col &&
+		r.Min.Row <= c.Row && c.Row <= r.Max.Row
+}
+
+// ContainsRange returns true if every cell of other is inside the range,
+// ranges on different sheets and empty ranges are never contained
+func (r Range) ContainsRange(other Range) bool {
+	if r.Sheet != other.Sheet || r.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+
+	other = other.normalize()
+	return r.Contains(other.Min) && r.Contains(other.Max)
+}
+
+// IsSubsetOf returns true if every cell of the range is inside other,
+// see ContainsRange
+func (r Range) IsSubsetOf(other Range) bool {
+	return other.ContainsRange(r)
+}
+
+// IsSupersetOf returns true if every cell of other is inside the range,
+// it is the same as ContainsRange
+func (r Range) IsSupersetOf(other Range) bool {
+	return r.ContainsRange(other)
+}
+
+// ContainsColumn returns true if column with zero based index col
+// is one of the range columns
+func (r Range) ContainsColumn(col uint16) bool {
+	r = r.normalize()
+	return r.Min.Col <= col && col <= r.Max.Col
+}
+
+// ContainsRow returns true if row with zero based index row
+// is one of the range rows
+func (r Range) ContainsRow(row uint16) bool {
+	r = r.normalize()
+	return r.Min.Row <= row && row <= r.Max.Row
+}
+
+// Including returns the smallest range that contains both the range
+// and the cell, for empty range it returns range of the single cell
+func (r Range) Including(c CellAddr) Range {
+	if r.IsEmpty() {
+		return Range{Min: c, Max: c, Sheet: r.Sheet}
 	}
 
-	w := max.Col - min.Col + 1
-	h := max.Row - min.Row + 1
+	r = r.normalize()
 
-	return int(w * h)
+	if c.Col < r.Min.Col {
+		r.Min.Col = c.Col
+	}
+	if c.Col > r.Max.Col {
+		r.Max.Col = c.Col
+	}
+	if c.Row < r.Min.Row {
+		r.Min.Row = c.Row
+	}
+	if c.Row > r.Max.Row {
+		r.Max.Row = c.Row
+	}
+
+	return r
+}
+
+// Half-open variants of the geometry methods treat range as [Min, Max),
+// the way Go slices and the API GridRange do, so Max column and Max row
+// do not belong to the range. Range with Min equal to Max is empty.
+
+// SquareHalfOpen is Square of the half-open range
+func (r Range) SquareHalfOpen() int {
+	if r.IsEmpty() {
+		return 0
+	}
+
+	r = r.normalize()
+	return (int(r.Max.Col) - int(r.Min.Col)) * (int(r.Max.Row) - int(r.Min.Row))
+}
+
+// CellsHalfOpen is Cells of the half-open range
+func (r Range) CellsHalfOpen() []CellAddr {
+	r = r.normalize()
+
+	n := r.SquareHalfOpen()
+	if n == 0 {
+		return nil
+	}
+
+	return cells(r.Min, CellAddr{r.Max.Col - 1, r.Max.Row - 1}, n)
 }
 
-// Move moves entire range
-// TODO: test
+// ContainsHalfOpen is Contains of the half-open range
+func (r Range) ContainsHalfOpen(c CellAddr) bool {
+	r = r.normalize()
+	return r.Min.Col <= c.Col && c.Col < r.Max.Col &&
+		r.Min.Row <= c.Row && c.Row < r.Max.Row
+}
+
+// cells returns n cells between min and max inclusive row by row
+func cells(min, max CellAddr, n int) []CellAddr {
+	res := make([]CellAddr, 0, n)
+
+	for row := int(min.Row); row <= int(max.Row); row++ {
+		for col := int(min.Col); col <= int(max.Col); col++ {
+			res = append(res, CellAddr{uint16(col), uint16(row)})
+		}
+	}
+
+	return res
+}
+
+// Move moves entire range by ver rows down and hor columns right,
+// see CellAddr.Move. Sheet, workbook and open bounds of the range are
+// kept, bound is still written open if its row or column is not moved.
 func (r Range) Move(ver, hor int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r.Min, r.Max = r.Min.Move(ver, hor), r.Max.Move(ver, hor)
+	return r
+}
+
+// Down returns range moved n rows down, range stops at the grid edge
+// keeping its size
+func (r Range) Down(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	return r.Move(int(shiftRow(r.Max.Row, n))-int(r.Max.Row), 0)
+}
+
+// Up returns range moved n rows up, range stops at the grid edge
+// keeping its size
+func (r Range) Up(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	return r.Move(int(shiftRow(r.Min.Row, -n))-int(r.Min.Row), 0)
+}
+
+// Right returns range moved n columns right, range stops at the grid
+// edge keeping its size
+func (r Range) Right(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	return r.Move(0, int(shift(r.Max.Col, n))-int(r.Max.Col))
+}
+
+// Left returns range moved n columns left, range stops at the grid edge
+// keeping its size
+func (r Range) Left(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	return r.Move(0, int(shift(r.Min.Col, -n))-int(r.Min.Col))
+}
+
+// RelativeTo returns range with both corners relative to the origin
+// (see CellAddr.RelativeTo), RelativeTo(r.Min) moves range to A1
+func (r Range) RelativeTo(origin CellAddr) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	return Range{
+		Min:   r.Min.RelativeTo(origin),
+		Max:   r.Max.RelativeTo(origin),
+		Sheet: r.Sheet,
+	}
+}
+
+// TopLeft returns top left corner of the range
+func (r Range) TopLeft() CellAddr {
+	return r.normalize().Min
+}
+
+// TopRight returns top right corner of the range
+func (r Range) TopRight() CellAddr {
+	r = r.normalize()
+	return CellAddr{r.Max.Col, r.Min.Row}
+}
+
+// BottomRight returns bottom right corner of the range
+func (r Range) BottomRight() CellAddr {
+	return r.normalize().Max
+}
+
+// BottomLeft returns bottom left corner of the range
+func (r Range) BottomLeft() CellAddr {
+	r = r.normalize()
+	return CellAddr{r.Min.Col, r.Max.Row}
+}
+
+// Corners returns corners of the range clockwise starting from the top
+// left: top left, top right, bottom right and bottom left.
+// For a single cell range all corners are the same cell.
+func (r Range) Corners() [4]CellAddr {
+	return [4]CellAddr{r.TopLeft(), r.TopRight(), r.BottomRight(), r.BottomLeft()}
+}
+
+// AlignTo returns range of the same size moved so that its top left
+// corner is the anchor. Part of the range that does not fit into
+// addressable grid is cut off.
+func (r Range) AlignTo(anchor CellAddr) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+
+	return Range{
+		Min: anchor,
+		Max: CellAddr{
+			shift(anchor.Col, int(r.Max.Col)-int(r.Min.Col)),
+			shiftRow(anchor.Row, int(r.Max.Row)-int(r.Min.Row)),
+		},
+		Sheet: r.Sheet,
+	}
+}
+
+// Adjacent checks if ranges share a full edge and returns range that
+// consists of the both ranges, EmptyRange is returned if they are not.
+// Ranges that overlap, touch diagonally, share only part of the edge or
+// are on different sheets are not adjacent.
+func (r Range) Adjacent(other Range) (Range, bool) {
+	r, other = r.normalize(), other.normalize()
+
+	if r.Sheet != other.Sheet || r.IsEmpty() || other.IsEmpty() {
+		return EmptyRange, false
+	}
+
+	// make r the top left one of the two ranges
+	if other.Min.Col < r.Min.Col || other.Min.Row < r.Min.Row {
+		r, other = other, r
+	}
+
+	sameRows := r.Min.Row == other.Min.Row && r.Max.Row == other.Max.Row
+	sameCols := r.Min.Col == other.Min.Col && r.Max.Col == other.Max.Col
+
+	switch {
+	case sameRows && int(r.Max.Col)+1 == int(other.Min.Col):
+		r.Max.Col = other.Max.Col
+	case sameCols && int(r.Max.Row)+1 == int(other.Min.Row):
+		r.Max.Row = other.Max.Row
+	default:
+		return EmptyRange, false
+	}
+
+	return r, true
+}
+
+// FlipH returns range mirrored horizontally about the column about,
+// column c becomes 2*about-c. Part of the range that goes out of the grid
+// is cut off, EmptyRange is returned if the whole range goes out of it.
+func (r Range) FlipH(about uint16) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+
+	var ok bool
+	if r.Min.Col, r.Max.Col, ok = reflect(r.Min.Col, r.Max.Col, about, maxCol); !ok {
+		return EmptyRange
+	}
+
+	return r
+}
+
+// FlipV returns range mirrored vertically about the row about,
+// row c becomes 2*about-c. Part of the range that goes out of the grid
+// is cut off, EmptyRange is returned if the whole range goes out of it.
+func (r Range) FlipV(about uint16) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+
+	var ok bool
+	if r.Min.Row, r.Max.Row, ok = reflect(r.Min.Row, r.Max.Row, about, maxRow); !ok {
+		return EmptyRange
+	}
+
+	return r
+}
+
+// Rotate90 returns range rotated 90 degrees clockwise about the cell
+// about, as the grid is seen on the screen: cells to the right of the
+// pivot go below it and cells below it go to the left. Cells are rotated
+// as a whole, so width and height of the result are swapped height and
+// width of the range, both odd and even. Part of the range that goes out
+// of the grid is cut off, EmptyRange is returned if the whole range goes
+// out of it.
+func (r Range) Rotate90(about CellAddr) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	col, row := int(about.Col), int(about.Row)
+
+	// columns of the result are rows of the range and vice versa
+	left, right := col+row-int(r.Max.Row), col+row-int(r.Min.Row)
+	top, bottom := row+int(r.Min.Col)-col, row+int(r.Max.Col)-col
+
+	if right < 0 || left > maxCol || bottom < 0 || top > maxRow {
+		return EmptyRange
+	}
+
 	return Range{
-		r.Min.Move(ver, hor),
-		r.Max.Move(ver, hor),
+		Min:   CellAddr{clamp(left, maxCol), clamp(top, maxRow)},
+		Max:   CellAddr{clamp(right, maxCol), clamp(bottom, maxRow)},
+		Sheet: r.Sheet,
+	}
+}
+
+// reflect reflects span from min to max about the axis clamping result
+// to the range from zero to limit, ok is false if the whole span goes
+// out of it
+func reflect(min, max, axis uint16, limit int) (uint16, uint16, bool) {
+	lo, hi := 2*int(axis)-int(max), 2*int(axis)-int(min)
+	if hi < 0 || lo > limit {
+		return 0, 0, false
+	}
+
+	return clamp(lo, limit), clamp(hi, limit), true
+}
+
+// Intersects returns true if ranges have common cells, ranges on different
+// sheets never intersect. Unlike Intersect it does not build the overlap.
+func (r Range) Intersects(other Range) bool {
+	if r.Sheet != other.Sheet || r.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+
+	r, other = r.normalize(), other.normalize()
+
+	return r.Min.Col <= other.Max.Col && other.Min.Col <= r.Max.Col &&
+		r.Min.Row <= other.Max.Row && other.Min.Row <= r.Max.Row
+}
+
+// Intersect returns range of the cells that are in both ranges,
+// EmptyRange is returned if ranges do not intersect
+func (r Range) Intersect(other Range) (Range, bool) {
+	if !r.Intersects(other) {
+		return EmptyRange, false
+	}
+
+	r, other = r.normalize(), other.normalize()
+
+	if other.Min.Col > r.Min.Col {
+		r.Min.Col = other.Min.Col
+	}
+	if other.Min.Row > r.Min.Row {
+		r.Min.Row = other.Min.Row
+	}
+	if other.Max.Col < r.Max.Col {
+		r.Max.Col = other.Max.Col
+	}
+	if other.Max.Row < r.Max.Row {
+		r.Max.Row = other.Max.Row
+	}
+
+	return r, true
+}
+
+// clip returns part of the range inside the grid of rows and cols,
+// EmptyRange is returned if there is no such part
+func (r Range) clip(rows, cols int) Range {
+	if rows < 1 || cols < 1 {
+		return EmptyRange
+	}
+
+	grid := Range{
+		Max:   CellAddr{shift(0, cols-1), shiftRow(0, rows-1)},
+		Sheet: r.Sheet,
+	}
+
+	res, ok := r.Intersect(grid)
+	if !ok {
+		return EmptyRange
 	}
+
+	res.WholeSheet, res.OpenRows, res.OpenColumns = false, false, false
+	return res
+}
+
+// SplitAt splits range into four quadrants around the pivot cell c:
+// top left, top right, bottom left and bottom right. Pivot is the top
+// left cell of the bottom right quadrant, so bottom right quadrant is
+// never empty while the others are EmptyRange if pivot is in the first
+// row or column of the range. ok is false if pivot is outside the range.
+func (r Range) SplitAt(c CellAddr) (tl, tr, bl, br Range, ok bool) {
+	if !r.Contains(c) {
+		return EmptyRange, EmptyRange, EmptyRange, EmptyRange, false
+	}
+
+	r = r.normalize()
+	tl, tr, bl = EmptyRange, EmptyRange, EmptyRange
+
+	br = Range{Min: c, Max: r.Max, Sheet: r.Sheet}
+
+	if c.Row > r.Min.Row {
+		tr = Range{
+			Min:   CellAddr{c.Col, r.Min.Row},
+			Max:   CellAddr{r.Max.Col, c.Row - 1},
+			Sheet: r.Sheet,
+		}
+	}
+
+	if c.Col > r.Min.Col {
+		bl = Range{
+			Min:   CellAddr{r.Min.Col, c.Row},
+			Max:   CellAddr{c.Col - 1, r.Max.Row},
+			Sheet: r.Sheet,
+		}
+	}
+
+	if c.Row > r.Min.Row && c.Col > r.Min.Col {
+		tl = Range{
+			Min:   r.Min,
+			Max:   CellAddr{c.Col - 1, c.Row - 1},
+			Sheet: r.Sheet,
+		}
+	}
+
+	return tl, tr, bl, br, true
 }
 
-// ID extracts spreadsheet id from given url
+// ChunkByCells splits range into contiguous ranges of at most maxCells
+// cells that cover it top to bottom. Range is split along rows into
+// bands of whole rows, rows wider than maxCells are split into parts of
+// maxCells columns left to right. It returns nil for the empty range or
+// not positive maxCells.
+func (r Range) ChunkByCells(maxCells int) []Range {
+	if r.IsEmpty() || maxCells < 1 {
+		return nil
+	}
+
+	r = r.normalize()
+	r.WholeSheet = false
+
+	if w := r.Width(); w > maxCells {
+		res := make([]Range, 0, r.Height()*((w+maxCells-1)/maxCells))
+
+		for row := int(r.Min.Row); row <= int(r.Max.Row); row++ {
+			for col := int(r.Min.Col); col <= int(r.Max.Col); col += maxCells {
+				chunk := r
+				chunk.Min = CellAddr{uint16(col), uint16(row)}
+				chunk.Max = CellAddr{shift(uint16(col), maxCells-1), uint16(row)}
+				if chunk.Max.Col > r.Max.Col {
+					chunk.Max.Col = r.Max.Col
+				}
+				res = append(res, chunk)
+			}
+		}
+
+		return res
+	}
+
+	rows := maxCells / r.Width()
+	res := make([]Range, 0, (r.Height()+rows-1)/rows)
+
+	for row := int(r.Min.Row); row <= int(r.Max.Row); row += rows {
+		chunk := r
+		chunk.Min.Row = uint16(row)
+		if end := row + rows - 1; end < int(r.Max.Row) {
+			chunk.Max.Row = uint16(end)
+		}
+		res = append(res, chunk)
+	}
+
+	return res
+}
+
+// Subtract returns non overlapping ranges that cover cells of the range
+// that are not in the hole: up to one range above the hole, one below it
+// and one on each side of it. Result contains the range itself if hole does
+// not intersect it and it is empty if hole covers whole range.
+func (r Range) Subtract(hole Range) []Range {
+	r, hole = r.normalize(), hole.normalize()
+
+	if r.IsEmpty() {
+		return nil
+	}
+
+	if !r.Intersects(hole) {
+		return []Range{r}
+	}
+
+	// overlapping rows
+	top, bottom := r.Min.Row, r.Max.Row
+
+	res := make([]Range, 0, 4)
+
+	if r.Min.Row < hole.Min.Row {
+		top = hole.Min.Row
+		res = append(res, Range{
+			Min:   r.Min,
+			Max:   CellAddr{r.Max.Col, top - 1},
+			Sheet: r.Sheet,
+		})
+	}
+
+	if hole.Max.Row < r.Max.Row {
+		bottom = hole.Max.Row
+		res = append(res, Range{
+			Min:   CellAddr{r.Min.Col, bottom + 1},
+			Max:   r.Max,
+			Sheet: r.Sheet,
+		})
+	}
+
+	if r.Min.Col < hole.Min.Col {
+		res = append(res, Range{
+			Min:   CellAddr{r.Min.Col, top},
+			Max:   CellAddr{hole.Min.Col - 1, bottom},
+			Sheet: r.Sheet,
+		})
+	}
+
+	if hole.Max.Col < r.Max.Col {
+		res = append(res, Range{
+			Min:   CellAddr{hole.Max.Col + 1, top},
+			Max:   CellAddr{r.Max.Col, bottom},
+			Sheet: r.Sheet,
+		})
+	}
+
+	return res
+}
+
+// PadTop returns range extended by n rows up, clamped at the first row.
+// Negative n is treated as zero.
+func (r Range) PadTop(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	r.Min.Row = shiftRow(r.Min.Row, -nonNegative(n))
+	return r
+}
+
+// PadBottom returns range extended by n rows down, clamped at the last
+// addressable row. Negative n is treated as zero.
+func (r Range) PadBottom(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	r.Max.Row = shiftRow(r.Max.Row, nonNegative(n))
+	return r
+}
+
+// PadLeft returns range extended by n columns to the left, clamped at
+// the first column. Negative n is treated as zero.
+func (r Range) PadLeft(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	r.Min.Col = shift(r.Min.Col, -nonNegative(n))
+	return r
+}
+
+// PadRight returns range extended by n columns to the right, clamped at
+// the last addressable column. Negative n is treated as zero.
+func (r Range) PadRight(n int) Range {
+	if r.IsEmpty() {
+		return r
+	}
+
+	r = r.normalize()
+	r.Max.Col = shift(r.Max.Col, nonNegative(n))
+	return r
+}
+
+// shift adds d to the column v clamping result to the addressable columns
+func shift(v uint16, d int) uint16 {
+	return clamp(int(v)+d, maxCol)
+}
+
+// shiftRow adds d to the row v clamping result to the addressable rows
+func shiftRow(v uint16, d int) uint16 {
+	return clamp(int(v)+d, maxRow)
+}
+
+// clamp returns v clamped to the range from zero to max
+func clamp(v, max int) uint16 {
+	switch {
+	case v < 0:
+		return 0
+	case v > max:
+		return uint16(max)
+	}
+
+	return uint16(v)
+}
+
+// nonNegative returns n or zero if n is negative
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// FNV-1a 64 bit parameters used by Range.Hash
+const (
+	fnvOffset64 uint64 = 14695981039346656037
+	fnvPrime64  uint64 = 1099511628211
+)
+
+// Hash returns stable hash of the range corners and sheet name,
+// it does not allocate unlike using String as a map key.
+// Equal ranges have equal hashes, but different ranges may collide.
+func (r Range) Hash() uint64 {
+	h := fnvOffset64
+
+	for i := 0; i < len(r.Sheet); i++ {
+		h ^= uint64(r.Sheet[i])
+		h *= fnvPrime64
+	}
+
+	for _, k := range [2]uint32{r.Min.Key(), r.Max.Key()} {
+		for i := 0; i < 4; i++ {
+			h ^= uint64(byte(k >> (8 * i)))
+			h *= fnvPrime64
+		}
+	}
+
+	return h
+}
+
+// ID extracts spreadsheet id from given url.
+// Besides google docs links (docs.google.com/spreadsheets/d/<id>) it
+// accepts google drive links (drive.google.com/file/d/<id>/view and
+// drive.google.com/open?id=<id>) since spreadsheet id is a drive file id.
+// Returned errors wrap ErrInvalidURL, ErrWrongHost or ErrNotFound
+// so they can be checked with errors.Is.
 func ID(src string) (string, error) {
 	if len(src) == 0 {
-		return "", fmt.Errorf("spreadsheet id: link is empty")
+		return "", fmt.Errorf("spreadsheet id: %w: link is empty", ErrInvalidURL)
 	}
 
 	link, err := url.Parse(src)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("spreadsheet id: %w: %v", ErrInvalidURL, err)
 	}
 
-	if host := link.Hostname(); "docs.google.com" != host {
-		return "", fmt.Errorf(
-			"spreadsheet id: '%s' not a google docs hostname", host,
-		)
+	switch host := link.Hostname(); host {
+	case "docs.google.com":
+		if res := RegexpSpeadsheetId.FindStringSubmatch(src); len(res) == 2 {
+			return res[1], nil
+		}
+	case "drive.google.com":
+		return driveID(link)
+	default:
+		return "", fmt.Errorf("spreadsheet id: %w: '%s'", ErrWrongHost, host)
 	}
 
-	if res := RegexpSpeadsheetId.FindStringSubmatch(src); len(res) == 2 {
+	return "", ErrNotFound
+}
+
+// driveID extracts file id from google drive url
+func driveID(link *url.URL) (string, error) {
+	if res := RegexpDriveFileId.FindStringSubmatch(link.Path); len(res) == 2 {
 		return res[1], nil
 	}
 
+	if id := link.Query().Get("id"); regexpId.MatchString(id) {
+		return id, nil
+	}
+
 	return "", ErrNotFound
 }
@@ -0,0 +1,71 @@
+package spreadsheet
+
+import "fmt"
+
+// RangeBuilder builds range from separately computed bounds,
+// bounds are validated once by Build
+type RangeBuilder struct {
+	min, max       [2]int
+	hasMin, hasMax bool
+	sheet          string
+}
+
+// NewRangeBuilder returns builder without bounds and sheet name
+func NewRangeBuilder() *RangeBuilder {
+	return &RangeBuilder{}
+}
+
+// MinCell sets zero based column and row of the top left cell
+func (b *RangeBuilder) MinCell(col, row int) *RangeBuilder {
+	b.min, b.hasMin = [2]int{col, row}, true
+	return b
+}
+
+// MaxCell sets zero based column and row of the bottom right cell
+func (b *RangeBuilder) MaxCell(col, row int) *RangeBuilder {
+	b.max, b.hasMax = [2]int{col, row}, true
+	return b
+}
+
+// Sheet sets name of the sheet range belongs to
+func (b *RangeBuilder) Sheet(name string) *RangeBuilder {
+	b.sheet = name
+	return b
+}
+
+// Build returns range with the configured bounds. It returns error if
+// any of the cells is not set or is out of the grid, and error wrapping
+// ErrInvalidRange if the min cell is to the right of or below the max cell.
+func (b *RangeBuilder) Build() (Range, error) {
+	if !b.hasMin || !b.hasMax {
+		return EmptyRange, fmt.Errorf("build range: both min and max cells must be set")
+	}
+
+	min, err := builderCell(b.min)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("build range: min %v", err)
+	}
+
+	max, err := builderCell(b.max)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("build range: max %v", err)
+	}
+
+	r := Range{Min: min, Max: max, Sheet: b.sheet}
+	if !r.IsValid() {
+		return EmptyRange, fmt.Errorf("build range: %w: %v is after %v", ErrInvalidRange, min, max)
+	}
+
+	return r, nil
+}
+
+// builderCell converts column and row to the cell address
+func builderCell(c [2]int) (CellAddr, error) {
+	col, row := c[0], c[1]
+
+	if col < 0 || col > maxCol || row < 0 || row > maxRow {
+		return InvalidCellAddr, fmt.Errorf("cell (%d, %d) is out of the grid", col, row)
+	}
+
+	return CellAddr{uint16(col), uint16(row)}, nil
+}
@@ -0,0 +1,40 @@
+package spreadsheet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRangeBuilder(t *testing.T) {
+	r, err := NewRangeBuilder().MinCell(1, 4).MaxCell(25, 2302).Sheet("Sheet1").Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if res := r.String(); res != "Sheet1!B5:Z2303" {
+		t.Errorf("Build() = %s, want Sheet1!B5:Z2303", res)
+	}
+
+	tt := []struct {
+		name    string
+		builder *RangeBuilder
+	}{
+		{"no min", NewRangeBuilder().MaxCell(1, 1)},
+		{"no max", NewRangeBuilder().MinCell(1, 1)},
+		{"negative", NewRangeBuilder().MinCell(-1, 0).MaxCell(1, 1)},
+		{"out of grid", NewRangeBuilder().MinCell(0, 0).MaxCell(1, 65536)},
+		// last row of uint16 is reserved for InvalidCellAddr
+		{"last row", NewRangeBuilder().MinCell(0, 0).MaxCell(65535, 65535)},
+	}
+
+	for _, tc := range tt {
+		if r, err := tc.builder.Build(); err == nil {
+			t.Errorf("%s: Build() = %v, want error", tc.name, r)
+		}
+	}
+
+	_, err = NewRangeBuilder().MinCell(2, 2).MaxCell(1, 3).Build()
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("Build() error = %v, want %v", err, ErrInvalidRange)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build go1.23
+
+package spreadsheet
+
+import "iter"
+
+// All returns sequence of all cells of the range row by row, it is the
+// same as Cells but does not allocate the slice of cells
+func (r Range) All() iter.Seq[CellAddr] {
+	return func(yield func(CellAddr) bool) {
+		if r.IsEmpty() {
+			return
+		}
+
+		r = r.normalize()
+
+		for row := int(r.Min.Row); row <= int(r.Max.Row); row++ {
+			for col := int(r.Min.Col); col <= int(r.Max.Col); col++ {
+				if !yield(CellAddr{uint16(col), uint16(row)}) {
+					return
+				}
+			}
+		}
+	}
+}
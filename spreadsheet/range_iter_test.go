@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package spreadsheet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRangeAll(t *testing.T) {
+	for _, rng := range []string{"A1:A1", "A1:B2", "C2:A1"} {
+		r, err := NewRange(rng)
+		if err != nil {
+			t.Fatalf("unable to create range '%s': %v", rng, err)
+		}
+
+		var res, want []string
+		for c := range r.All() {
+			res = append(res, c.String())
+		}
+		for _, c := range r.Cells() {
+			want = append(want, c.String())
+		}
+
+		if strings.Join(res, ",") != strings.Join(want, ",") {
+			t.Errorf("Range{%v}.All() = %v, want %v", r, res, want)
+		}
+	}
+
+	r, err := NewRange("A1:C3")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	var n int
+	for c := range r.All() {
+		if n++; c.String() == "B2" {
+			break
+		}
+	}
+	if n != 5 {
+		t.Errorf("Range{%v}.All() visited %d cells before break, want 5", r, n)
+	}
+
+	for c := range EmptyRange.All() {
+		t.Errorf("EmptyRange.All() yielded %v", c)
+	}
+}
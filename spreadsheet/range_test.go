@@ -1,11 +1,21 @@
 package spreadsheet
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
 
 func TestID(t *testing.T) {
 	tt := map[string]string{
 		"https://docs.google.com/spreadsheets/d/232jfks": "232jfks",
 		"https://docs.yahoo.com/spreadsheets/d/23sksfjh": "",
+		"https://drive.google.com/file/d/1a-B_c/view":    "1a-B_c",
+		"https://drive.google.com/open?id=1a-B_c":        "1a-B_c",
+		"https://drive.google.com/open?id=1a/../b":       "",
+		"https://drive.google.com/drive/folders/":        "",
 		"fhejk": "",
 		"":      "",
 	}
@@ -19,6 +29,23 @@ func TestID(t *testing.T) {
 	}
 }
 
+func TestIDErrors(t *testing.T) {
+	tt := map[string]error{
+		"":      ErrInvalidURL,
+		"%zz":   ErrInvalidURL,
+		"fhejk": ErrWrongHost,
+		"https://docs.yahoo.com/spreadsheets/d/23sksfjh": ErrWrongHost,
+		"https://docs.google.com/document/":              ErrNotFound,
+		"https://drive.google.com/drive/my-drive":        ErrNotFound,
+	}
+
+	for u, w := range tt {
+		if _, err := ID(u); !errors.Is(err, w) {
+			t.Errorf("ID(%s) = %v, want %v", u, err, w)
+		}
+	}
+}
+
 func TestDigitsCount(t *testing.T) {
 	tt := []struct{ i, base, want int }{
 		{0, 10, 1},
@@ -67,20 +94,21 @@ func TestColRune(t *testing.T) {
 func TestColNum(t *testing.T) {
 
 	tt := map[string]uint16{
-		"ф1":  0,
-		"11":  0,
-		"ЁЁ":  0,
-		"A":   1,
-		"B":   2,
-		"E":   5,
-		"Z":   26,
-		"aa":  27,
-		"AB":  28,
-		"AZ":  52,
-		"YZ":  676,
-		"ZZ":  702,
-		"aac": 705,
-		"XFD": 16384,
+		"ф1":    0,
+		"11":    0,
+		"ЁЁ":    0,
+		"A":     1,
+		"B":     2,
+		"E":     5,
+		"Z":     26,
+		"aa":    27,
+		"AB":    28,
+		"AZ":    52,
+		"YZ":    676,
+		"ZZ":    702,
+		"aac":   705,
+		"XFD":   16384,
+		"SHEET": 0,
 	}
 
 	for n, w := range tt {
@@ -96,6 +124,12 @@ func TestCellAddrString(t *testing.T) {
 		"A1":   {0, 0},
 		"A2":   {0, 1},
 		"XFD3": {16383, 2},
+		"Z1":   {25, 0},
+		"AA1":  {26, 0},
+		"ZZ10": {701, 9},
+		"AAA1": {702, 0},
+
+		"CRXP65536": {65535, 65535},
 	}
 	for w, a := range tt {
 		if a.String() != w {
@@ -104,19 +138,82 @@ func TestCellAddrString(t *testing.T) {
 	}
 }
 
+func BenchmarkCellAddrString(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = CellAddr{uint16(i), uint16(i >> 16)}.String()
+	}
+}
+
+func TestCellAddrKey(t *testing.T) {
+	tt := map[CellAddr]uint32{
+		{0, 0}:         0,
+		{0, 1}:         1,
+		{1, 0}:         1 << 16,
+		{16383, 2}:     16383<<16 | 2,
+		{65535, 65535}: 1<<32 - 1,
+	}
+
+	for c, w := range tt {
+		if k := c.Key(); k != w {
+			t.Errorf("CellAddr{%d, %d}.Key() = %d, want %d", c.Col, c.Row, k, w)
+		}
+	}
+}
+
+func TestA1(t *testing.T) {
+	tt := []struct {
+		col, row uint16
+		want     string
+	}{
+		{0, 0, "A1"},
+		{1, 4, "B5"},
+		{26, 22, "AA23"},
+		{16383, 2, "XFD3"},
+		{maxCol, maxRow, "CRXP65535"},
+		{math.MaxUint16, math.MaxUint16, ""},
+		{0, math.MaxUint16, ""},
+	}
+
+	for _, tc := range tt {
+		if res := A1(tc.col, tc.row); res != tc.want {
+			t.Errorf("A1(%d, %d) = %s, want %s", tc.col, tc.row, res, tc.want)
+		}
+	}
+}
+
 func TestNewCellAddr(t *testing.T) {
 	tt := map[string]struct {
 		res CellAddr
 		err bool
 	}{
-		"a1":    {CellAddr{0, 0}, false},
-		"b5":    {CellAddr{1, 4}, false},
-		"Z2303": {CellAddr{25, 2302}, false},
-		"AA23":  {CellAddr{26, 22}, false},
-		"ЁцЭ":   {emptyCellAddr, true},
-		"":      {emptyCellAddr, true},
-		"5A1":   {emptyCellAddr, true},
-		"XFD3":  {CellAddr{16383, 2}, false},
+		"a1":     {CellAddr{0, 0}, false},
+		"b5":     {CellAddr{1, 4}, false},
+		"Z2303":  {CellAddr{25, 2302}, false},
+		"AA23":   {CellAddr{26, 22}, false},
+		"ЁцЭ":    {InvalidCellAddr, true},
+		"":       {InvalidCellAddr, true},
+		"5A1":    {InvalidCellAddr, true},
+		"XFD3":   {CellAddr{16383, 2}, false},
+		"A1B":    {InvalidCellAddr, true},
+		"A1.5":   {InvalidCellAddr, true},
+		"ABC":    {InvalidCellAddr, true},
+		"A 1":    {InvalidCellAddr, true},
+		"A+1":    {InvalidCellAddr, true},
+		"Aц1":    {InvalidCellAddr, true},
+		"A0":     {InvalidCellAddr, true},
+		"Z0":     {InvalidCellAddr, true},
+		"A00":    {InvalidCellAddr, true},
+		"$B$5":   {CellAddr{1, 4}, false},
+		"$B5":    {CellAddr{1, 4}, false},
+		"B$5":    {CellAddr{1, 4}, false},
+		"$$B5":   {InvalidCellAddr, true},
+		"B$$5":   {InvalidCellAddr, true},
+		"B5$":    {InvalidCellAddr, true},
+		"B$":     {InvalidCellAddr, true},
+		"A65535": {CellAddr{0, 65534}, false},
+		"A65536": {InvalidCellAddr, true},
 	}
 
 	for a, w := range tt {
@@ -129,16 +226,31 @@ func TestNewCellAddr(t *testing.T) {
 				"NewCellAddr(%s) = (%v, %v), want %v", a, addr, err, w.res,
 			)
 		}
+
+		if addr.IsValid() == isErr {
+			t.Errorf("NewCellAddr(%s).IsValid() = %t with error %v", a, addr.IsValid(), err)
+		}
 	}
 }
 
 func TestNewRange(t *testing.T) {
 	tt := map[string]bool{
-		"A1:A2":      false,
-		"A2:A1":      false,
-		"aa23:XFD27": false,
-		"sd":         true,
-		"5F:Ad":      true,
+		"A1:A2":            false,
+		"A2:A1":            false,
+		"aa23:XFD27":       false,
+		"sd":               true,
+		"5F:Ad":            true,
+		"Sheet1!A1:B2":     false,
+		"'My sheet'!A1:B2": false,
+		"!A1:B2":           true,
+		"''!A1:B2":         true,
+		"'A:B'!A1:B2":      false,
+		"'Sheet1!A1:B2":    true,
+		"'Sheet1'A1:B2":    true,
+		"$A$1:$B$2":        false,
+		"Sheet1!$A1:B$2":   false,
+		"A1:B2:C3":         true,
+		"Sheet1!A1:B2:C3":  true,
 	}
 
 	for r, e := range tt {
@@ -147,12 +259,364 @@ func TestNewRange(t *testing.T) {
 		}
 	}
 
+	for _, r := range []string{"Sheet1!A1:Sheet2!B2", "'Sheet 1'!A1:'Sheet 2'!B2", "'Sheet 1'!A1:Sheet2!B2"} {
+		res, err := NewRange(r)
+		if err == nil || !strings.Contains(err.Error(), "cross-sheet") {
+			t.Errorf("NewRange(%s) = (%v, %v), want cross-sheet range error", r, res, err)
+		}
+	}
+
+	// unquoted sheet name can not contain !
+	for _, r := range []string{"Sheet1!Sheet2!A1:B2", "Sheet1!!A1", "Hi!there!"} {
+		if res, err := NewRange(r); err == nil {
+			t.Errorf("NewRange(%s) = %v, expected error", r, res)
+		}
+	}
+}
+
+func FuzzCellAddr(f *testing.F) {
+	f.Add(uint16(0), uint16(0))
+	f.Add(uint16(25), uint16(2302))
+	f.Add(uint16(16383), uint16(9))
+	f.Add(uint16(math.MaxUint16), uint16(math.MaxUint16-1))
+
+	f.Fuzz(func(t *testing.T, col, row uint16) {
+		// last row is not addressable, see TestNewCellAddrLastRow
+		if row == math.MaxUint16 {
+			t.Skip()
+		}
+
+		c := CellAddr{col, row}
+
+		res, err := NewCellAddr(c.String())
+		if err != nil || !res.Equal(c) {
+			t.Errorf("NewCellAddr(%s) = (%#v, %v), want %#v", c, res, err, c)
+		}
+	})
+}
+
+func FuzzNewCellAddr(f *testing.F) {
+	for _, s := range []string{"A1", "$B$5", "A0", "A00", "CRXP65536", "CRXQ1", "A65537", "", "$", "Aц1"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		c, err := NewCellAddr(s)
+		if err != nil {
+			if !c.Equal(InvalidCellAddr) {
+				t.Errorf("NewCellAddr(%q) = (%v, %v), want InvalidCellAddr on error", s, c, err)
+			}
+			return
+		}
+
+		if !c.IsValid() {
+			t.Errorf("NewCellAddr(%q) = %#v without error, want valid address", s, c)
+		}
+
+		// parsed address is written canonically, e.g without $ and leading zeros
+		res, err := NewCellAddr(c.String())
+		if err != nil || !res.Equal(c) {
+			t.Errorf("NewCellAddr(%q) = %#v, but NewCellAddr(%s) = (%#v, %v)", s, c, c, res, err)
+		}
+	})
+}
+
+func TestNewCellAddrLastRow(t *testing.T) {
+	// the last cell of the address space is InvalidCellAddr
+	if c, err := NewCellAddr("CRXP65536"); err == nil {
+		t.Errorf("NewCellAddr(CRXP65536) = %#v, want error", c)
+	}
+
+	if r, err := NewRange("CRXP65536:A1"); err == nil {
+		t.Errorf("NewRange(CRXP65536:A1) = %#v, want error", r)
+	}
+
+	r, err := NewRange("CRXP65535:A1")
+	if err != nil || r.IsEmpty() || !r.IsValid() {
+		t.Errorf("NewRange(CRXP65535:A1) = (%#v, %v), want valid range", r, err)
+	}
+}
+
+func TestRangeFromAnchor(t *testing.T) {
+	tt := []struct {
+		anchor        CellAddr
+		width, height int
+		want          string
+	}{
+		{CellAddr{1, 1}, 5, 10, "B2:F11"},
+		{CellAddr{0, 0}, 1, 1, "A1:A1"},
+		{CellAddr{0, maxRow}, 2, 5, "A65535:B65535"},
+	}
+
+	for _, tc := range tt {
+		if res := RangeFromAnchor(tc.anchor, tc.width, tc.height); res.String() != tc.want {
+			t.Errorf("RangeFromAnchor(%v, %d, %d) = %v, want %s", tc.anchor, tc.width, tc.height, res, tc.want)
+		}
+	}
+
+	for _, d := range [][2]int{{0, 1}, {1, 0}, {-1, 5}} {
+		if res := RangeFromAnchor(CellAddr{1, 1}, d[0], d[1]); !res.IsEmpty() {
+			t.Errorf("RangeFromAnchor(B2, %d, %d) = %v, want EmptyRange", d[0], d[1], res)
+		}
+	}
+}
+
+func TestNewRangeSheet(t *testing.T) {
+	tt := map[string]string{
+		"A1:B2":                  "",
+		"Sheet1!A1:B2":           "Sheet1",
+		"'My sheet'!A1:B2":       "My sheet",
+		"'John''s'!A1:B2":        "John's",
+		"'Hi!there'!A1:B2":       "Hi!there",
+		"'Q1 Report'!c3:d10":     "Q1 Report",
+		"'A:B Comparison'!A1:B2": "A:B Comparison",
+		"'x!:y''s'!A1:B2":        "x!:y's",
+	}
+
+	for s, w := range tt {
+		r, err := NewRange(s)
+		if err != nil || r.Sheet != w {
+			t.Errorf("NewRange(%s) = (%#v, %v), want sheet %s", s, r, err, w)
+		}
+	}
+}
+
+func TestNewRangeWholeSheet(t *testing.T) {
+	tt := map[string]string{
+		"Sheet1!":     "Sheet1",
+		"'My sheet'!": "My sheet",
+		"'John''s'!":  "John's",
+		"'Hi!there'!": "Hi!there",
+	}
+
+	for s, w := range tt {
+		r, err := NewRange(s)
+		if err != nil || r.Sheet != w || !r.WholeSheet {
+			t.Errorf("NewRange(%s) = (%#v, %v), want whole sheet %s", s, r, err, w)
+			continue
+		}
+
+		if res := r.String(); res != s {
+			t.Errorf("NewRange(%s).String() = %s, want %s", s, res, s)
+		}
+
+		if !r.Contains(CellAddr{16383, maxRow}) {
+			t.Errorf("NewRange(%s).Contains(XFD65535) = false, want true", s)
+		}
+	}
+
+	r, err := NewRange("Sheet1!")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if res := r.WithoutSheet().String(); strings.HasSuffix(res, "!") {
+		t.Errorf("NewRange(Sheet1!).WithoutSheet() = %s, want bounded range", res)
+	}
+
+	if res := r.Move(1, 0).String(); strings.HasSuffix(res, "!") {
+		t.Errorf("NewRange(Sheet1!).Move(1, 0) = %s, want bounded range", res)
+	}
+
+	for _, s := range []string{"!", "''!"} {
+		if res, err := NewRange(s); err == nil {
+			t.Errorf("NewRange(%s) = %v, expected error", s, res)
+		}
+	}
+}
+
+func TestNewRangeWorkbook(t *testing.T) {
+	tt := []struct {
+		rng      string
+		workbook string
+		sheet    string
+	}{
+		{"[Book1.xlsx]Sheet1!A1:B2", "Book1.xlsx", "Sheet1"},
+		{"'[Book1.xlsx]My Sheet'!A1:B2", "Book1.xlsx", "My Sheet"},
+		{"'[Q1 Report.xlsx]Sheet1'!A1:B2", "Q1 Report.xlsx", "Sheet1"},
+		{"[Book1.XLSM]Sheet1!", "Book1.XLSM", "Sheet1"},
+		{"'[Archive] Data'!A1:B2", "", "[Archive] Data"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil || r.Workbook != tc.workbook || r.Sheet != tc.sheet {
+			t.Errorf(
+				"NewRange(%s) = (%#v, %v), want workbook %s and sheet %s",
+				tc.rng, r, err, tc.workbook, tc.sheet,
+			)
+			continue
+		}
+
+		if res := r.String(); res != tc.rng {
+			t.Errorf("NewRange(%s).String() = %s, want %s", tc.rng, res, tc.rng)
+		}
+	}
+
+	// workbook is not sent to the API
+	if res := quoteName("[Book1.xlsx]Sheet1!A1:B2"); res != "Sheet1!A1:B2" {
+		t.Errorf("quoteName([Book1.xlsx]Sheet1!A1:B2) = %s, want Sheet1!A1:B2", res)
+	}
+
+	if res, err := NewRange("[Book1.xlsx]!A1:B2"); err == nil {
+		t.Errorf("NewRange([Book1.xlsx]!A1:B2) = %v, expected error", res)
+	}
+}
+
+func TestNewRangeOpen(t *testing.T) {
+	tt := []struct {
+		rng        string
+		rows, cols bool
+		max        CellAddr
+	}{
+		{"A1:B", true, false, CellAddr{1, maxRow}},
+		{"Sheet1!C5:$AA", true, false, CellAddr{26, maxRow}},
+		{"A1:2", false, true, CellAddr{65535, 1}},
+		{"'My sheet'!B2:10", false, true, CellAddr{65535, 9}},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("NewRange(%s) error: %v", tc.rng, err)
+			continue
+		}
+
+		if r.OpenRows != tc.rows || r.OpenColumns != tc.cols || !r.Max.Equal(tc.max) {
+			t.Errorf("NewRange(%s) = %#v, want open rows %t, columns %t and max %v",
+				tc.rng, r, tc.rows, tc.cols, tc.max)
+		}
+
+		if res := r.String(); res != strings.ReplaceAll(tc.rng, "$", "") {
+			t.Errorf("NewRange(%s).String() = %s, want %s", tc.rng, res, tc.rng)
+		}
+	}
+
+	r, err := NewRange("A1:B")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	// range is bounded as soon as its last row is changed
+	bounded := r
+	bounded.Max.Row = 99
+	if res := bounded.String(); res != "A1:B100" {
+		t.Errorf("NewRange(A1:B) with Max.Row 99 = %s, want A1:B100", res)
+	}
+
+	if res := r.clip(1000, 26).String(); res != "A1:B1000" {
+		t.Errorf("NewRange(A1:B).clip(1000, 26) = %s, want A1:B1000", res)
+	}
+
+	for _, s := range []string{"A1:", "A1:B2C", "A1:0"} {
+		if res, err := NewRange(s); err == nil {
+			t.Errorf("NewRange(%s) = %v, expected error", s, res)
+		}
+	}
+}
+
+func TestNewRangeCase(t *testing.T) {
+	// sheet name case is preserved, columns are always uppercase
+	tt := map[string]string{
+		"Sheet1!a1:b2":         "Sheet1!A1:B2",
+		"sheet1!A1:b2":         "sheet1!A1:B2",
+		"mySheet!aa1:Ab2":      "mySheet!AA1:AB2",
+		"'My Sheet'!xfd1:xfd3": "'My Sheet'!XFD1:XFD3",
+		"a1:b2":                "A1:B2",
+	}
+
+	for s, w := range tt {
+		r, err := NewRange(s)
+		if err != nil {
+			t.Errorf("NewRange(%s) = (%v, %v), want %s", s, r, err, w)
+			continue
+		}
+
+		if res := r.String(); res != w {
+			t.Errorf("NewRange(%s).String() = %s, want %s", s, res, w)
+		}
+	}
+}
+
+func TestNeedsQuote(t *testing.T) {
+	tt := map[string]bool{
+		"":          false,
+		"Data":      false,
+		"Sheet1":    false,
+		"my_sheet":  false,
+		"Лист1":     false,
+		"Q1 Report": true,
+		"1Q":        true,
+		"2024":      true,
+		"Sales-Q1":  true,
+		"John's":    true,
+		"A:B":       true,
+		"Hi!":       true,
+		"A1":        true,
+		"xfd3":      true,
+	}
+
+	for name, w := range tt {
+		if res := needsQuote(name); res != w {
+			t.Errorf("needsQuote(%s) = %t, want %t", name, res, w)
+		}
+	}
+}
+
+func TestQuoteName(t *testing.T) {
+	tt := map[string]string{
+		"Sheet1":             "Sheet1",
+		"Sales (2024)":       "'Sales (2024)'",
+		"'Sales (2024)'":     "'Sales (2024)'",
+		"Sales (2024)!A1:B2": "'Sales (2024)'!A1:B2",
+		"Sales (2024)!A:C":   "'Sales (2024)'!A:C",
+		"'Sales (2024)'!A:C": "'Sales (2024)'!A:C",
+		"John's!A1:B2":       "'John''s'!A1:B2",
+		"Sheet1!A1:B2":       "Sheet1!A1:B2",
+		"B2:D10":             "B2:D10",
+		"A:C":                "A:C",
+		"Sales (2024)!":      "'Sales (2024)'!",
+		"Q1 Report!1:5":      "'Q1 Report'!1:5",
+		"'Q1 Report'!1:5":    "'Q1 Report'!1:5",
+		"2024":               "'2024'",
+		"A1":                 "A1",
+		"B5":                 "B5",
+		"$B$5":               "$B$5",
+	}
+
+	for name, w := range tt {
+		if res := quoteName(name); res != w {
+			t.Errorf("quoteName(%s) = %s, want %s", name, res, w)
+		}
+	}
+}
+
+func TestSheetTitle(t *testing.T) {
+	tt := map[string]string{
+		"Sheet1":        "Sheet1",
+		"Q1 Report":     "Q1 Report",
+		"'Q1 Report'":   "Q1 Report",
+		"'John''s'":     "John's",
+		"'Hi!there'":    "Hi!there",
+		"'unterminated": "'unterminated",
+	}
+
+	for name, w := range tt {
+		if res := sheetTitle(name); res != w {
+			t.Errorf("sheetTitle(%s) = %s, want %s", name, res, w)
+		}
+	}
 }
 
 func TestRangeString(t *testing.T) {
 	tt := map[Range]string{
-		Range{CellAddr{0, 0}, CellAddr{16383, 2}}: "A1:XFD3",
-		Range{CellAddr{1, 4}, CellAddr{25, 2302}}: "B5:Z2303",
+		{Min: CellAddr{0, 0}, Max: CellAddr{16383, 2}}:                 "A1:XFD3",
+		{Min: CellAddr{1, 4}, Max: CellAddr{25, 2302}}:                 "B5:Z2303",
+		{Min: CellAddr{0, 0}, Max: CellAddr{1, 1}, Sheet: "Sheet1"}:    "Sheet1!A1:B2",
+		{Min: CellAddr{0, 0}, Max: CellAddr{1, 1}, Sheet: "John's"}:    "'John''s'!A1:B2",
+		{Min: CellAddr{0, 0}, Max: CellAddr{1, 1}, Sheet: "Q1 Report"}: "'Q1 Report'!A1:B2",
+		EmptyRange:                     "",
+		EmptyRange.WithSheet("Sheet1"): "",
 	}
 
 	for r, w := range tt {
@@ -174,6 +638,10 @@ func TestRangeMove(t *testing.T) {
 		{"J23:L27", -10, -5, "E13:G17"},
 		{"B33:C44", 0, 0, "B33:C44"},
 		{"B33:A2", 0, 10, "K2:L33"},
+		{"Sheet1!A1:B2", 1, 1, "Sheet1!B2:C3"},
+		{"[Book1.xlsx]Sheet1!A1:B2", 1, 1, "[Book1.xlsx]Sheet1!B2:C3"},
+		{"Sheet1!A2:B", 0, 1, "Sheet1!B2:C"},
+		{"A1:2", 1, 0, "A2:3"},
 	}
 
 	for _, tc := range tt {
@@ -190,29 +658,1083 @@ func TestRangeMove(t *testing.T) {
 			)
 		}
 	}
+
+	r, err := NewRange("[Book1.xlsx]Sheet1!")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	res := r.Move(1, 0)
+	if res.Workbook != r.Workbook || !res.WholeSheet || res.OpenRows || res.OpenColumns {
+		t.Errorf("Range{%v}.Move(1, 0) = %#v, want flags of %#v", r, res, r)
+	}
 }
 
-func TestSquare(t *testing.T) {
+func TestCellAddrFluentMove(t *testing.T) {
+	c := CellAddr{2, 2}
+
+	tt := map[string]CellAddr{
+		"D6": c.Down(3).Right(1),
+		"B2": c.Up(1).Left(1),
+		"A1": c.Up(10).Left(10),
+		"C3": c.Down(2).Up(2),
+	}
+
+	for w, res := range tt {
+		if res.String() != w {
+			t.Errorf("moved %v = %v, want %s", c, res, w)
+		}
+	}
+
+	if res := (CellAddr{math.MaxUint16, 0}).Right(1); res.Col != math.MaxUint16 {
+		t.Errorf("CellAddr.Right() over the edge = %v, want last column", res)
+	}
+
+	// last cell of the address space is not InvalidCellAddr
+	if res := (CellAddr{}).Down(1 << 20).Right(1 << 20); res != lastCellAddr || !res.IsValid() {
+		t.Errorf("CellAddr.Down().Right() over the edge = %#v, want %#v", res, lastCellAddr)
+	}
+}
+
+func TestCellAddrOffsetBy(t *testing.T) {
+	c := CellAddr{2, 2}
+
 	tt := []struct {
-		trange string
-		square int
+		col, row int
+		want     string
 	}{
-		{"A1:A1", 1},
-		{"J23:L27", 15},
-		{"B2:A1", 4},
-		{"C5:D9", 10},
-		{"D9:C5", 10},
+		{0, 0, "C3"},
+		{1, 3, "D6"},
+		{3, 1, "F4"},
+		{-2, -2, "A1"},
 	}
 
 	for _, tc := range tt {
-		r, err := NewRange(tc.trange)
+		res, err := c.OffsetBy(tc.col, tc.row)
 		if err != nil {
-			t.Errorf("unable to create range '%s': %v", tc.trange, err)
+			t.Errorf("OffsetBy(%d, %d) error: %v", tc.col, tc.row, err)
+			continue
 		}
 
-		if sq := r.Square(); sq != tc.square {
-			t.Errorf("Range{%v}.Square() = %d, want %d", r, sq, tc.square)
+		if res.String() != tc.want {
+			t.Errorf("OffsetBy(%d, %d) = %v, want %s", tc.col, tc.row, res, tc.want)
+		}
+	}
+
+	for _, d := range [][2]int{{-3, 0}, {0, -3}, {math.MaxUint16, 0}, {0, math.MaxUint16 - 2}} {
+		if res, err := c.OffsetBy(d[0], d[1]); err == nil {
+			t.Errorf("OffsetBy(%d, %d) = %v, expected error", d[0], d[1], res)
+		}
+	}
+
+	if res, err := (CellAddr{maxCol - 1, math.MaxUint16}).OffsetBy(1, 0); err == nil {
+		t.Errorf("OffsetBy(1, 0) of the unaddressable row = %#v, expected error", res)
+	}
+}
+
+func TestRangeFluentMove(t *testing.T) {
+	r, err := NewRange("C3:D4")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	tt := map[string]Range{
+		"E6:F7": r.Down(3).Right(2),
+		"B2:C3": r.Up(1).Left(1),
+		"A1:B2": r.Up(10).Left(10),
+		"C3:D4": r.Down(2).Up(2),
+	}
+
+	for w, res := range tt {
+		if res.String() != w {
+			t.Errorf("moved %v = %v, want %s", r, res, w)
+		}
+	}
+
+	edge := Range{Min: CellAddr{0, maxRow - 1}, Max: CellAddr{0, maxRow}}
+	if res := edge.Down(5); res != edge {
+		t.Errorf("Range{%v}.Down(5) = %v, want %v", edge, res, edge)
+	}
+}
+
+func TestRangeRelativeTo(t *testing.T) {
+	tt := []struct {
+		rng    string
+		origin CellAddr
+		result string
+	}{
+		{"B2:C3", CellAddr{1, 1}, "A1:B2"},
+		{"J23:L27", CellAddr{0, 0}, "J23:L27"},
+		{"J23:L27", CellAddr{5, 10}, "E13:G17"},
+		{"A1:A1", CellAddr{0, 0}, "A1:A1"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		if res := r.RelativeTo(tc.origin); res.String() != tc.result {
+			t.Errorf(
+				"Range{%v}.RelativeTo(%v) = %v, want %s",
+				r, tc.origin, res, tc.result,
+			)
+		}
+	}
+}
+
+func TestRangeHash(t *testing.T) {
+	tt := []string{
+		"A1:A1",
+		"A1:A2",
+		"B1:B1",
+		"Sheet1!A1:A1",
+		"Sheet2!A1:A1",
+		"'Sheet 1'!A1:XFD3",
+	}
+
+	seen := make(map[uint64]string, len(tt))
+
+	for _, s := range tt {
+		r, err := NewRange(s)
+		if err != nil {
+			t.Fatalf("unable to create range '%s': %v", s, err)
+		}
+
+		h := r.Hash()
+
+		if again, _ := NewRange(s); again.Hash() != h {
+			t.Errorf("Range{%v}.Hash() is not stable", r)
+		}
+
+		if other, ok := seen[h]; ok {
+			t.Errorf("Range{%v}.Hash() collides with %s", r, other)
+		}
+		seen[h] = s
+	}
+}
+
+func TestRangeCorners(t *testing.T) {
+	tt := map[string][4]string{
+		"B2:D5": {"B2", "D2", "D5", "B5"},
+		"D5:B2": {"B2", "D2", "D5", "B5"},
+		"C3:C3": {"C3", "C3", "C3", "C3"},
+		"A1:A9": {"A1", "A1", "A9", "A9"},
+	}
+
+	for s, w := range tt {
+		r, err := NewRange(s)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", s, err)
+		}
+
+		var res [4]string
+		for i, c := range r.Corners() {
+			res[i] = c.String()
 		}
+
+		if res != w {
+			t.Errorf("Range{%v}.Corners() = %v, want %v", r, res, w)
+		}
+	}
+}
+
+func TestRangeCells(t *testing.T) {
+	tt := []struct {
+		rng      string
+		cells    []string
+		halfOpen []string
+	}{
+		{"A1:A1", []string{"A1"}, nil},
+		{"A1:B2", []string{"A1", "B1", "A2", "B2"}, []string{"A1"}},
+		{"C2:A1", []string{"A1", "B1", "C1", "A2", "B2", "C2"}, []string{"A1", "B1"}},
 	}
 
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		var res []string
+		for _, c := range r.Cells() {
+			res = append(res, c.String())
+		}
+		if strings.Join(res, ",") != strings.Join(tc.cells, ",") {
+			t.Errorf("Range{%v}.Cells() = %v, want %v", r, res, tc.cells)
+		}
+
+		res = res[:0]
+		for _, c := range r.CellsHalfOpen() {
+			res = append(res, c.String())
+		}
+		if strings.Join(res, ",") != strings.Join(tc.halfOpen, ",") {
+			t.Errorf("Range{%v}.CellsHalfOpen() = %v, want %v", r, res, tc.halfOpen)
+		}
+
+		if sq := r.SquareHalfOpen(); sq != len(tc.halfOpen) {
+			t.Errorf("Range{%v}.SquareHalfOpen() = %d, want %d", r, sq, len(tc.halfOpen))
+		}
+	}
+}
+
+func TestRangePerimeter(t *testing.T) {
+	tt := []struct {
+		rng  string
+		want []string
+	}{
+		{"B2:B2", []string{"B2"}},
+		{"A1:C1", []string{"A1", "B1", "C1"}},
+		{"C1:C3", []string{"C1", "C2", "C3"}},
+		{"A1:B2", []string{"A1", "B1", "B2", "A2"}},
+		{"C3:A1", []string{"A1", "B1", "C1", "C2", "C3", "B3", "A3", "A2"}},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		var res []string
+		for _, c := range r.Perimeter() {
+			res = append(res, c.String())
+		}
+
+		if strings.Join(res, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("Range{%s}.Perimeter() = %v, want %v", tc.rng, res, tc.want)
+		}
+	}
+
+	if res := EmptyRange.Perimeter(); res != nil {
+		t.Errorf("EmptyRange.Perimeter() = %v, want nil", res)
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tt := []struct {
+		rng            string
+		cell           CellAddr
+		want, halfOpen bool
+	}{
+		{"A1:B2", CellAddr{0, 0}, true, true},
+		{"A1:B2", CellAddr{1, 1}, true, false},
+		{"A1:B2", CellAddr{1, 0}, true, false},
+		{"A1:B2", CellAddr{2, 0}, false, false},
+		{"B2:A1", CellAddr{0, 1}, true, false},
+		{"C3:C3", CellAddr{2, 2}, true, false},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		if res := r.Contains(tc.cell); res != tc.want {
+			t.Errorf("Range{%v}.Contains(%v) = %t, want %t", r, tc.cell, res, tc.want)
+		}
+
+		if res := r.ContainsHalfOpen(tc.cell); res != tc.halfOpen {
+			t.Errorf("Range{%v}.ContainsHalfOpen(%v) = %t, want %t", r, tc.cell, res, tc.halfOpen)
+		}
+	}
+}
+
+func TestRangeContainsRange(t *testing.T) {
+	tt := []struct {
+		rng, other string
+		want       bool
+	}{
+		{"A1:D4", "B2:C3", true},
+		{"A1:D4", "A1:D4", true},
+		{"D4:A1", "C3:B2", true},
+		{"A1:D4", "B2:E3", false},
+		{"B2:C3", "A1:D4", false},
+		{"Sheet1!A1:D4", "Sheet1!B2:C3", true},
+		{"Sheet1!A1:D4", "Sheet2!B2:C3", false},
+		{"Sheet1!", "Sheet1!B2:C3", true},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		other, err := NewRange(tc.other)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		if res := r.ContainsRange(other); res != tc.want {
+			t.Errorf("Range{%s}.ContainsRange(%s) = %t, want %t", tc.rng, tc.other, res, tc.want)
+		}
+
+		if res := r.IsSupersetOf(other); res != tc.want {
+			t.Errorf("Range{%s}.IsSupersetOf(%s) = %t, want %t", tc.rng, tc.other, res, tc.want)
+		}
+
+		if res := other.IsSubsetOf(r); res != tc.want {
+			t.Errorf("Range{%s}.IsSubsetOf(%s) = %t, want %t", tc.other, tc.rng, res, tc.want)
+		}
+	}
+
+	r, err := NewRange("A1:D4")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if r.ContainsRange(EmptyRange) || EmptyRange.IsSubsetOf(r) {
+		t.Errorf("Range{A1:D4}.ContainsRange(EmptyRange) = true, want false")
+	}
+}
+
+func TestRangeContainsColumnRow(t *testing.T) {
+	// bounds are not normalized, B1:D6 with swapped columns
+	r := Range{Min: CellAddr{3, 0}, Max: CellAddr{1, 5}}
+
+	for col, want := range map[uint16]bool{0: false, 1: true, 2: true, 3: true, 4: false} {
+		if res := r.ContainsColumn(col); res != want {
+			t.Errorf("Range{%v}.ContainsColumn(%d) = %t, want %t", r, col, res, want)
+		}
+	}
+
+	for row, want := range map[uint16]bool{0: true, 5: true, 6: false} {
+		if res := r.ContainsRow(row); res != want {
+			t.Errorf("Range{%v}.ContainsRow(%d) = %t, want %t", r, row, res, want)
+		}
+	}
+
+	if EmptyRange.ContainsColumn(0) || EmptyRange.ContainsRow(0) {
+		t.Errorf("EmptyRange.ContainsColumn(0) or ContainsRow(0) = true, want false")
+	}
+}
+
+func TestRangeAlignTo(t *testing.T) {
+	tt := []struct {
+		rng    string
+		anchor CellAddr
+		result string
+	}{
+		{"C3:D5", CellAddr{0, 0}, "A1:B3"},
+		{"A1:B3", CellAddr{2, 2}, "C3:D5"},
+		{"D5:C3", CellAddr{25, 9}, "Z10:AA12"},
+		{"B2:B2", CellAddr{0, 0}, "A1:A1"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		if res := r.AlignTo(tc.anchor); res.String() != tc.result {
+			t.Errorf("Range{%v}.AlignTo(%v) = %v, want %s", r, tc.anchor, res, tc.result)
+		}
+	}
+
+	edge := CellAddr{maxCol - 1, maxRow}
+	r := Range{Max: CellAddr{5, 5}}.AlignTo(edge)
+	if want := (Range{Min: edge, Max: lastCellAddr}); r != want {
+		t.Errorf("Range{A1:F6}.AlignTo(%v) = %v, want %v", edge, r, want)
+	}
+}
+
+func TestRangeAdjacent(t *testing.T) {
+	tt := []struct {
+		a, b   string
+		result string
+		ok     bool
+	}{
+		{"A1:B2", "C1:D2", "A1:D2", true},
+		{"C1:D2", "A1:B2", "A1:D2", true},
+		{"A1:B2", "A3:B5", "A1:B5", true},
+		{"A3:B5", "B2:A1", "A1:B5", true},
+		{"A1:B2", "C2:D3", "", false},
+		{"A1:B2", "C3:D4", "", false},
+		{"A1:B2", "D1:E2", "", false},
+		{"A1:B2", "B1:C2", "", false},
+		{"A1:B2", "A1:B2", "", false},
+		{"Sheet1!A1:B2", "Sheet2!C1:D2", "", false},
+		{"Sheet1!A1:B2", "Sheet1!C1:D2", "Sheet1!A1:D2", true},
+	}
+
+	for _, tc := range tt {
+		a, err := NewRange(tc.a)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.a, err)
+		}
+
+		b, err := NewRange(tc.b)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.b, err)
+		}
+
+		res, ok := a.Adjacent(b)
+		if ok != tc.ok || (ok && res.String() != tc.result) {
+			t.Errorf(
+				"Range{%v}.Adjacent(%v) = (%v, %t), want (%s, %t)",
+				a, b, res, ok, tc.result, tc.ok,
+			)
+		}
+	}
+}
+
+func TestRangeFlip(t *testing.T) {
+	tt := []struct {
+		rng   string
+		about uint16
+		h, v  string
+	}{
+		{"B2:C3", 4, "G2:H3", "B7:C8"},
+		{"B2:C3", 1, "A2:B3", "B1:C2"},
+		{"C3:B2", 2, "C2:D3", "B3:C4"},
+		{"E5:F6", 2, "A5:A6", "E1:F1"},
+		// whole range goes out of the grid
+		{"C1:D1", 0, "", "C1:D1"},
+		{"C3:D4", 0, "", ""},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		if res := r.FlipH(tc.about); res.String() != tc.h {
+			t.Errorf("Range{%v}.FlipH(%d) = %v, want %s", r, tc.about, res, tc.h)
+		}
+
+		if res := r.FlipV(tc.about); res.String() != tc.v {
+			t.Errorf("Range{%v}.FlipV(%d) = %v, want %s", r, tc.about, res, tc.v)
+		}
+	}
+
+	edge := Range{Min: CellAddr{0, 0}, Max: CellAddr{1, 1}}
+	if res := edge.FlipH(maxCol); !res.IsEmpty() {
+		t.Errorf("Range{%v}.FlipH(%d) = %#v, want EmptyRange", edge, maxCol, res)
+	}
+
+	if res := edge.FlipV(maxRow); !res.IsEmpty() {
+		t.Errorf("Range{%v}.FlipV(%d) = %#v, want EmptyRange", edge, maxRow, res)
+	}
+}
+
+func TestRangeIntersect(t *testing.T) {
+	tt := []struct {
+		rng, other string
+		result     string
+	}{
+		{"A1:C3", "B2:D4", "B2:C3"},
+		{"A1:C3", "C3:E5", "C3:C3"},
+		{"B2:D4", "A1:E5", "B2:D4"},
+		{"A1:C3", "D1:E3", ""},
+		{"A1:C3", "A4:C5", ""},
+		{"Sheet1!A1:C3", "Sheet2!A1:C3", ""},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		other, err := NewRange(tc.other)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.other, err)
+		}
+
+		if res := r.Intersects(other); res != (tc.result != "") {
+			t.Errorf("Range{%v}.Intersects(%v) = %t, want %t", r, other, res, tc.result != "")
+		}
+
+		res, ok := r.Intersect(other)
+		if ok != (tc.result != "") || (ok && res.String() != tc.result) || (!ok && !res.IsEmpty()) {
+			t.Errorf("Range{%v}.Intersect(%v) = (%v, %t), want %s", r, other, res, ok, tc.result)
+		}
+	}
+
+	if EmptyRange.Intersects(EmptyRange) {
+		t.Errorf("EmptyRange.Intersects(EmptyRange) = true, want false")
+	}
+}
+
+func TestRangeSplitAt(t *testing.T) {
+	r, err := NewRange("B2:D4")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	tt := []struct {
+		pivot CellAddr
+		want  [4]string
+	}{
+		{CellAddr{2, 2}, [4]string{"B2:B2", "C2:D2", "B3:B4", "C3:D4"}},
+		{CellAddr{1, 1}, [4]string{"", "", "", "B2:D4"}},
+		{CellAddr{3, 1}, [4]string{"", "", "B2:C4", "D2:D4"}},
+		{CellAddr{1, 3}, [4]string{"", "B2:D3", "", "B4:D4"}},
+	}
+
+	for _, tc := range tt {
+		tl, tr, bl, br, ok := r.SplitAt(tc.pivot)
+		if !ok {
+			t.Errorf("Range{%v}.SplitAt(%v) ok = false, want true", r, tc.pivot)
+			continue
+		}
+
+		for i, q := range []Range{tl, tr, bl, br} {
+			res := q.String()
+			if q.IsEmpty() {
+				res = ""
+			}
+
+			if res != tc.want[i] {
+				t.Errorf("Range{%v}.SplitAt(%v)[%d] = %s, want %s", r, tc.pivot, i, res, tc.want[i])
+			}
+		}
+	}
+
+	if _, _, _, _, ok := r.SplitAt(CellAddr{0, 0}); ok {
+		t.Errorf("Range{%v}.SplitAt(A1) ok = true, want false", r)
+	}
+}
+
+func TestRangeRotate90(t *testing.T) {
+	tt := []struct {
+		rng   string
+		about CellAddr
+		want  string
+	}{
+		{"B2:D3", CellAddr{1, 1}, "A2:B4"},
+		{"B1:C1", CellAddr{0, 0}, "A2:A3"},
+		{"C3:C3", CellAddr{2, 2}, "C3:C3"},
+		{"E5:F5", CellAddr{3, 3}, "C5:C6"},
+		{"A1:B1", CellAddr{0, 0}, "A1:A2"},
+		{"A1:A3", CellAddr{0, 0}, "A1:A1"},
+		// whole range goes out of the grid
+		{"A1:A3", CellAddr{1, 0}, ""},
+		{"A3:A4", CellAddr{0, 0}, ""},
+		{"C1:D1", CellAddr{5, 0}, ""},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		if res := r.Rotate90(tc.about).String(); res != tc.want {
+			t.Errorf("Range{%v}.Rotate90(%v) = %s, want %s", r, tc.about, res, tc.want)
+		}
+	}
+
+	r, err := NewRange("Sheet1!D4:F5")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	res, about := r, CellAddr{4, 4}
+	for i := 0; i < 4; i++ {
+		res = res.Rotate90(about)
+	}
+	if res != r {
+		t.Errorf("Range{%v} rotated four times = %v, want %v", r, res, r)
+	}
+
+	if res := EmptyRange.Rotate90(about); !res.IsEmpty() {
+		t.Errorf("EmptyRange.Rotate90() = %v, want EmptyRange", res)
+	}
+}
+
+func TestRangeChunkByCells(t *testing.T) {
+	tt := []struct {
+		rng      string
+		maxCells int
+		want     []string
+	}{
+		{"A1:B5", 10, []string{"A1:B5"}},
+		{"A1:B5", 4, []string{"A1:B2", "A3:B4", "A5:B5"}},
+		{"Sheet1!B2:D3", 5, []string{"Sheet1!B2:D2", "Sheet1!B3:D3"}},
+		{"A1:E2", 2, []string{"A1:B1", "C1:D1", "E1:E1", "A2:B2", "C2:D2", "E2:E2"}},
+		{"A1:B5", 0, nil},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		var res []string
+		for _, chunk := range r.ChunkByCells(tc.maxCells) {
+			if n := chunk.Square(); n > tc.maxCells {
+				t.Errorf("Range{%s}.ChunkByCells(%d) chunk %v has %d cells", tc.rng, tc.maxCells, chunk, n)
+			}
+			res = append(res, chunk.String())
+		}
+
+		if strings.Join(res, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("Range{%s}.ChunkByCells(%d) = %v, want %v", tc.rng, tc.maxCells, res, tc.want)
+		}
+	}
+
+	if res := EmptyRange.ChunkByCells(10); res != nil {
+		t.Errorf("EmptyRange.ChunkByCells(10) = %v, want nil", res)
+	}
+}
+
+func TestRangeSubtract(t *testing.T) {
+	tt := []struct {
+		rng, hole string
+		result    []string
+	}{
+		{"A1:C3", "E5:F6", []string{"A1:C3"}},
+		{"A1:C3", "A1:C3", nil},
+		{"A1:C3", "A1:Z9", nil},
+		{"A1:C3", "B2:B2", []string{"A1:C1", "A3:C3", "A2:A2", "C2:C2"}},
+		{"A1:C3", "A1:A3", []string{"B1:C3"}},
+		{"A1:C3", "C3:D4", []string{"A1:C2", "A3:B3"}},
+		{"B2:D4", "A3:E3", []string{"B2:D2", "B4:D4"}},
+		{"Sheet1!A1:C3", "Sheet2!A1:C3", []string{"Sheet1!A1:C3"}},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		hole, err := NewRange(tc.hole)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.hole, err)
+		}
+
+		var res []string
+		for _, p := range r.Subtract(hole) {
+			res = append(res, p.String())
+		}
+
+		if strings.Join(res, ",") != strings.Join(tc.result, ",") {
+			t.Errorf("Range{%v}.Subtract(%v) = %v, want %v", r, hole, res, tc.result)
+		}
+	}
+}
+
+func TestEmptyRange(t *testing.T) {
+	if !EmptyRange.IsEmpty() {
+		t.Errorf("EmptyRange.IsEmpty() = false, want true")
+	}
+
+	for _, r := range []Range{{}, {Max: CellAddr{1, 1}}, {Min: CellAddr{3, 3}}} {
+		if r.IsEmpty() {
+			t.Errorf("Range{%v}.IsEmpty() = true, want false", r)
+		}
+	}
+
+	r := Range{Max: CellAddr{2, 2}}
+
+	if sq := EmptyRange.Square(); sq != 0 {
+		t.Errorf("EmptyRange.Square() = %d, want 0", sq)
+	}
+
+	if c := EmptyRange.Cells(); len(c) != 0 {
+		t.Errorf("EmptyRange.Cells() = %v, want empty", c)
+	}
+
+	if EmptyRange.Contains(CellAddr{0, 0}) {
+		t.Errorf("EmptyRange.Contains(A1) = true, want false")
+	}
+
+	if res, ok := r.Adjacent(EmptyRange); ok || !res.IsEmpty() {
+		t.Errorf("Range{%v}.Adjacent(EmptyRange) = (%v, %t), want EmptyRange", r, res, ok)
+	}
+
+	if res, ok := r.Adjacent(Range{Min: CellAddr{5, 5}, Max: CellAddr{6, 6}}); ok || !res.IsEmpty() {
+		t.Errorf("Range{%v}.Adjacent(F6:G7) = (%v, %t), want EmptyRange", r, res, ok)
+	}
+
+	if res := EmptyRange.Subtract(r); len(res) != 0 {
+		t.Errorf("EmptyRange.Subtract(%v) = %v, want empty", r, res)
+	}
+
+	if res := r.Subtract(EmptyRange); len(res) != 1 || res[0] != r {
+		t.Errorf("Range{%v}.Subtract(EmptyRange) = %v, want [%v]", r, res, r)
+	}
+
+	for name, res := range map[string]Range{
+		"Move":       EmptyRange.Move(1, 1),
+		"RelativeTo": EmptyRange.RelativeTo(CellAddr{1, 1}),
+		"AlignTo":    EmptyRange.AlignTo(CellAddr{1, 1}),
+		"PadTop":     EmptyRange.PadTop(1),
+		"PadRight":   EmptyRange.PadRight(1),
+	} {
+		if !res.IsEmpty() {
+			t.Errorf("EmptyRange.%s() = %v, want EmptyRange", name, res)
+		}
+	}
+}
+
+func TestRangeIsValid(t *testing.T) {
+	r, err := NewRange("B2:C3")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	tt := []struct {
+		rng  Range
+		want bool
+	}{
+		{r, true},
+		{r.Move(-1, -1), true},
+		{r.Move(-2, 0), false},
+		{r.Move(0, -2), false},
+		{Range{Min: r.Max, Max: r.Min}, false},
+		{Range{Min: r.Min, Max: r.Min}, true},
+		{EmptyRange, false},
+	}
+
+	for _, tc := range tt {
+		if res := tc.rng.IsValid(); res != tc.want {
+			t.Errorf("Range{%v..%v}.IsValid() = %t, want %t", tc.rng.Min, tc.rng.Max, res, tc.want)
+		}
+	}
+}
+
+func TestRangeIsSingle(t *testing.T) {
+	tt := []struct {
+		rng               string
+		cell, row, column bool
+	}{
+		{"B2:B2", true, true, true},
+		{"A1:C1", false, true, false},
+		{"C1:C5", false, false, true},
+		{"D4:A1", false, false, false},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		if res := r.IsSingleCell(); res != tc.cell {
+			t.Errorf("Range{%s}.IsSingleCell() = %t, want %t", tc.rng, res, tc.cell)
+		}
+
+		if res := r.IsSingleRow(); res != tc.row {
+			t.Errorf("Range{%s}.IsSingleRow() = %t, want %t", tc.rng, res, tc.row)
+		}
+
+		if res := r.IsSingleColumn(); res != tc.column {
+			t.Errorf("Range{%s}.IsSingleColumn() = %t, want %t", tc.rng, res, tc.column)
+		}
+	}
+
+	if EmptyRange.IsSingleCell() || EmptyRange.IsSingleRow() || EmptyRange.IsSingleColumn() {
+		t.Errorf("EmptyRange.IsSingle*() = true, want false")
+	}
+}
+
+func TestRangePad(t *testing.T) {
+	tt := []struct {
+		rng                      string
+		top, bottom, left, right int
+		result                   string
+	}{
+		{"B2:C3", 1, 0, 0, 0, "B1:C3"},
+		{"B2:C3", 0, 2, 0, 0, "B2:C5"},
+		{"B2:C3", 0, 0, 1, 0, "A2:C3"},
+		{"B2:C3", 0, 0, 0, 3, "B2:F3"},
+		{"B2:C3", 5, 0, 5, 0, "A1:C3"},
+		{"C3:B2", 1, 1, 1, 1, "A1:D4"},
+		{"B2:C3", -1, -1, -1, -1, "B2:C3"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.rng, err)
+		}
+
+		res := r.PadTop(tc.top).
+			PadBottom(tc.bottom).
+			PadLeft(tc.left).
+			PadRight(tc.right)
+
+		if res.String() != tc.result {
+			t.Errorf(
+				"Range{%v} padded by (%d, %d, %d, %d) = %v, want %s",
+				r, tc.top, tc.bottom, tc.left, tc.right, res, tc.result,
+			)
+		}
+	}
+
+	edge := Range{Max: lastCellAddr}
+	if res := edge.PadBottom(1).PadRight(1); res != edge {
+		t.Errorf("Range{%v} padded over the edge = %v, want %v", edge, res, edge)
+	}
+}
+
+func TestSquare(t *testing.T) {
+	tt := []struct {
+		trange string
+		square int
+	}{
+		{"A1:A1", 1},
+		{"J23:L27", 15},
+		{"B2:A1", 4},
+		{"C5:D9", 10},
+		{"D9:C5", 10},
+		{"A1:ZZ65535", 702 * 65535},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.trange)
+		if err != nil {
+			t.Errorf("unable to create range '%s': %v", tc.trange, err)
+		}
+
+		if sq := r.Square(); sq != tc.square {
+			t.Errorf("Range{%v}.Square() = %d, want %d", r, sq, tc.square)
+		}
+
+		if sq := r.Area64(); sq != int64(tc.square) {
+			t.Errorf("Range{%v}.Area64() = %d, want %d", r, sq, tc.square)
+		}
+	}
+
+	whole := Range{Max: lastCellAddr}
+	if sq, want := whole.Area64(), int64(maxCol+1)*(maxRow+1); sq != want {
+		t.Errorf("Range{%v}.Area64() = %d, want %d", whole, sq, want)
+	}
+
+	if sq := EmptyRange.Area64(); sq != 0 {
+		t.Errorf("EmptyRange.Area64() = %d, want 0", sq)
+	}
+
+}
+
+func TestRangeDescribe(t *testing.T) {
+	tt := []struct {
+		rng  string
+		want string
+	}{
+		{"B5:Z2303", "B5:Z2303 (25 cols × 2299 rows, 57,475 cells)"},
+		{"Sheet1!A1:A1", "Sheet1!A1:A1 (1 cols × 1 rows, 1 cells)"},
+		{"A1:J100", "A1:J100 (10 cols × 100 rows, 1,000 cells)"},
+		{"A1:CV10000", "A1:CV10000 (100 cols × 10000 rows, 1,000,000 cells)"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		if res := r.Describe(); res != tc.want {
+			t.Errorf("Range{%s}.Describe() = %q, want %q", tc.rng, res, tc.want)
+		}
+	}
+
+	if res := EmptyRange.Describe(); res != "empty range" {
+		t.Errorf("EmptyRange.Describe() = %q, want %q", res, "empty range")
+	}
+}
+
+func TestDensity(t *testing.T) {
+	r, err := NewRange("A1:B2")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	tt := []struct {
+		values [][]interface{}
+		want   float64
+	}{
+		{nil, 0},
+		{[][]interface{}{{"a", "b"}, {"c", 1.5}}, 1},
+		{[][]interface{}{{"a", ""}, {}}, 0.25},
+		{[][]interface{}{{"", "b", "extra"}, {"c"}, {"extra"}}, 0.5},
+	}
+
+	for _, tc := range tt {
+		if res := Density(r, tc.values); res != tc.want {
+			t.Errorf("Density(%v, %v) = %v, want %v", r, tc.values, res, tc.want)
+		}
+	}
+
+	if res := Density(EmptyRange, [][]interface{}{{"a"}}); res != 0 {
+		t.Errorf("Density(EmptyRange) = %v, want 0", res)
+	}
+}
+
+func TestDensify(t *testing.T) {
+	r, err := NewRange("B2:D3")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	tt := []struct {
+		values [][]interface{}
+		want   [][]interface{}
+	}{
+		{nil, [][]interface{}{{nil, nil, nil}, {nil, nil, nil}}},
+		{
+			[][]interface{}{{"a", "b", "c"}, {"d", "e", "f"}},
+			[][]interface{}{{"a", "b", "c"}, {"d", "e", "f"}},
+		},
+		{
+			[][]interface{}{{"a"}},
+			[][]interface{}{{"a", nil, nil}, {nil, nil, nil}},
+		},
+		{
+			[][]interface{}{{"", "b", "c", "extra"}, {}, {"extra"}},
+			[][]interface{}{{"", "b", "c"}, {nil, nil, nil}},
+		},
+	}
+
+	for _, tc := range tt {
+		if res := Densify(r, tc.values); fmt.Sprint(res) != fmt.Sprint(tc.want) {
+			t.Errorf("Densify(%v, %v) = %v, want %v", r, tc.values, res, tc.want)
+		}
+	}
+
+	if res := Densify(EmptyRange, [][]interface{}{{"a"}}); res != nil {
+		t.Errorf("Densify(EmptyRange) = %v, want nil", res)
+	}
+}
+
+func TestRangeColumnLabels(t *testing.T) {
+	tt := map[string]string{
+		"A1:C10":    "A,B,C",
+		"D5:B1":     "B,C,D",
+		"Y2:AB2":    "Y,Z,AA,AB",
+		"XFD1:XFD1": "XFD",
+	}
+
+	for rng, w := range tt {
+		r, err := NewRange(rng)
+		if err != nil {
+			t.Fatalf("unable to create range '%s': %v", rng, err)
+		}
+
+		if res := strings.Join(r.ColumnLabels(), ","); res != w {
+			t.Errorf("Range{%v}.ColumnLabels() = %s, want %s", r, res, w)
+		}
+	}
+
+	if res := EmptyRange.ColumnLabels(); len(res) != 0 {
+		t.Errorf("EmptyRange.ColumnLabels() = %v, want empty", res)
+	}
+}
+
+func TestRangeCellAt(t *testing.T) {
+	r, err := NewRange("B2:D3")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	for i, c := range r.Cells() {
+		if res, err := r.CellAt(i); err != nil || res != c {
+			t.Errorf("CellAt(%d) = %v, %v, want %v", i, res, err, c)
+		}
+	}
+
+	for _, i := range []int{-1, 6} {
+		if _, err := r.CellAt(i); err == nil {
+			t.Errorf("CellAt(%d) expected error", i)
+		}
+	}
+
+	if _, err := EmptyRange.CellAt(0); err == nil {
+		t.Errorf("EmptyRange.CellAt(0) expected error")
+	}
+}
+
+func TestRangeIncluding(t *testing.T) {
+	tt := []struct {
+		r    string
+		c    string
+		want string
+	}{
+		{"B2:C3", "B2", "B2:C3"},
+		{"B2:C3", "A1", "A1:C3"},
+		{"B2:C3", "E2", "B2:E3"},
+		{"B2:C3", "D10", "B2:D10"},
+		{"Sheet1!B2:C3", "A5", "Sheet1!A2:C5"},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.r)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		c, err := NewCellAddr(tc.c)
+		if err != nil {
+			t.Fatalf("unable to create cell: %v", err)
+		}
+
+		if res := r.Including(c).String(); res != tc.want {
+			t.Errorf("%s.Including(%s) = %s, want %s", tc.r, tc.c, res, tc.want)
+		}
+	}
+
+	c := CellAddr{Col: 1, Row: 1}
+	if res := EmptyRange.Including(c); res != (Range{Min: c, Max: c}) {
+		t.Errorf("EmptyRange.Including(%v) = %v, want %v:%v", c, res, c, c)
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tt := map[string]int{
+		"A":    0,
+		"b":    1,
+		"Z":    25,
+		"AA":   26,
+		"XFD":  16383,
+		"CRXP": math.MaxUint16,
+	}
+
+	for name, w := range tt {
+		i, err := ColumnToIndex(name)
+		if err != nil || i != w {
+			t.Errorf("ColumnToIndex(%s) = %d, %v, want %d", name, i, err, w)
+		}
+
+		if res := IndexToColumn(i); res != strings.ToUpper(name) {
+			t.Errorf("IndexToColumn(ColumnToIndex(%s)) = %s, want %s", name, res, strings.ToUpper(name))
+		}
+	}
+
+	for _, name := range []string{"", "A1", "CRXQ", "AAAAA"} {
+		if i, err := ColumnToIndex(name); err == nil {
+			t.Errorf("ColumnToIndex(%s) = %d, want error", name, i)
+		}
+	}
+
+	if res := IndexToColumn(-1); res != "" {
+		t.Errorf("IndexToColumn(-1) = %s, want empty string", res)
+	}
+}
+
+func TestRangeWithSheet(t *testing.T) {
+	r, err := NewRange("Sheet1!A1:B2")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if res := r.WithSheet("Q1 Report").String(); res != "'Q1 Report'!A1:B2" {
+		t.Errorf("WithSheet(Q1 Report) = %s, want 'Q1 Report'!A1:B2", res)
+	}
+
+	if res := r.WithoutSheet().String(); res != "A1:B2" {
+		t.Errorf("WithoutSheet() = %s, want A1:B2", res)
+	}
+
+	if r.Sheet != "Sheet1" {
+		t.Errorf("WithSheet changed original range sheet to %s", r.Sheet)
+	}
 }
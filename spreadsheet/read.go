@@ -0,0 +1,29 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// ReadAll returns values of the sheet as rows of strings, values are
+// converted the same way as by Copy. Rows are not padded, so rows may
+// have different length.
+func ReadAll(srv *sheets.Service, id, name string) ([][]string, error) {
+	var rows [][]string
+
+	err := VisitRows(srv, id, name, func(_ int, vals []interface{}) error {
+		row, err := appendStrings(make([]string, 0, len(vals)), vals)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read all: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,40 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "age", "city"},
+			{"alice"},
+			{},
+			{"bob", "25"},
+		},
+		"Sheet2": {
+			{"a", 1},
+		},
+	})
+
+	want := [][]string{
+		{"name", "age", "city"},
+		{"alice"},
+		{},
+		{"bob", "25"},
+	}
+
+	res, err := ReadAll(srv, "id", "Sheet1")
+	if err != nil {
+		t.Fatalf("ReadAll(Sheet1) error: %v", err)
+	}
+
+	if fmt.Sprintf("%q", res) != fmt.Sprintf("%q", want) {
+		t.Errorf("ReadAll(Sheet1) = %q, want %q", res, want)
+	}
+
+	if _, err := ReadAll(srv, "id", "Sheet2"); err == nil {
+		t.Errorf("ReadAll(Sheet2) expected error for non-string value")
+	}
+}
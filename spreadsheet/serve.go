@@ -0,0 +1,62 @@
+package spreadsheet
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// serveFlushEvery is a number of rows ServeCSV writes between flushes
+const serveFlushEvery = 1000
+
+// ServeCSV copies values of the sheet to the HTTP response as a csv file
+// download named filename. Response is flushed every few rows, so client
+// starts receiving the file while the copy is in progress, and requests
+// are made with the context of req, so copy stops if client goes away.
+//
+// Headers are sent with the first flush, error that happened after that
+// is only returned, it is up to the caller to log it.
+func ServeCSV(
+	w http.ResponseWriter,
+	req *http.Request,
+	srv *sheets.Service,
+	id, name, filename string,
+) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set(
+		"Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": filename}),
+	)
+
+	opts := CopyOptions{
+		Context:    req.Context(),
+		FlushEvery: serveFlushEvery,
+	}
+
+	dst := &responseWriter{CSVWriter: newCSVWriter(w, opts)}
+	dst.flusher, _ = w.(http.Flusher)
+
+	if err := CopyWithOptions(dst, srv, id, name, opts); err != nil {
+		return fmt.Errorf("serve csv: %w", err)
+	}
+
+	return nil
+}
+
+// responseWriter is a CSVWriter that flushes HTTP response after flush
+// of the written records
+type responseWriter struct {
+	CSVWriter
+	flusher http.Flusher
+}
+
+// Flush implements CSVWriter interface
+func (w *responseWriter) Flush() {
+	w.CSVWriter.Flush()
+
+	if w.flusher != nil && w.CSVWriter.Error() == nil {
+		w.flusher.Flush()
+	}
+}
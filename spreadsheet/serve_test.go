@@ -0,0 +1,41 @@
+package spreadsheet
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeCSV(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Sheet1": {
+			{"name", "age"},
+			{"alice", "30"},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/export", nil)
+
+	if err := ServeCSV(rec, req, srv, "id", "Sheet1", "report 2024.csv"); err != nil {
+		t.Fatalf("ServeCSV() error: %v", err)
+	}
+
+	if res, want := rec.Body.String(), "name,age\nalice,30\n"; res != want {
+		t.Errorf("ServeCSV() body = %q, want %q", res, want)
+	}
+
+	headers := map[string]string{
+		"Content-Type":        "text/csv; charset=utf-8",
+		"Content-Disposition": `attachment; filename="report 2024.csv"`,
+	}
+
+	for k, want := range headers {
+		if res := rec.Header().Get(k); res != want {
+			t.Errorf("ServeCSV() header %s = %q, want %q", k, res, want)
+		}
+	}
+
+	if !rec.Flushed {
+		t.Errorf("ServeCSV() response was not flushed")
+	}
+}
@@ -0,0 +1,138 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+var (
+	// ErrSheetNotFound error returns when spreadsheet has no sheet with given name
+	ErrSheetNotFound error = fmt.Errorf("sheet not found")
+	// ErrNamedRangeNotFound error returns when spreadsheet has no named range
+	// with given name
+	ErrNamedRangeNotFound error = fmt.Errorf("named range not found")
+)
+
+// Dimensions returns number of rows and columns of the sheet grid.
+// Grid size is not the size of the data, grid usually has empty rows
+// and columns after the data.
+// Empty name means first sheet of the spreadsheet.
+func Dimensions(srv *sheets.Service, id, name string) (rows, cols int, err error) {
+	props, err := sheetProperties(srv, id, name)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dimensions: %w", err)
+	}
+
+	if props.GridProperties == nil {
+		return 0, 0, fmt.Errorf("dimensions: sheet '%s' is not a grid", props.Title)
+	}
+
+	return int(props.GridProperties.RowCount), int(props.GridProperties.ColumnCount), nil
+}
+
+// ClipToSheet returns part of the range that is inside the grid of its
+// sheet, so request of the range does not fail because of the bounds
+// exceeding the grid. Whole sheet range becomes range of the whole grid.
+// EmptyRange is returned if range is entirely outside the grid.
+// Use ClipToIndex to avoid requesting grid dimensions for every range.
+func (r Range) ClipToSheet(srv *sheets.Service, id string) (Range, error) {
+	rows, cols, err := Dimensions(srv, id, r.Sheet)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("clip to sheet: %w", err)
+	}
+
+	return r.clip(rows, cols), nil
+}
+
+// ClipToIndex is ClipToSheet which takes grid dimensions from idx
+func (r Range) ClipToIndex(idx *SpreadsheetIndex) (Range, error) {
+	rows, cols, err := idx.Dimensions(r.Sheet)
+	if err != nil {
+		return EmptyRange, fmt.Errorf("clip to sheet: %w", err)
+	}
+
+	return r.clip(rows, cols), nil
+}
+
+// frozenRows returns number of frozen rows of the sheet of name,
+// name is A1 notation of the copied values. Zero is returned if range
+// of name does not start at the first row.
+func frozenRows(srv *sheets.Service, id, name string) (int, error) {
+	r, err := NewRange(name)
+	if err != nil {
+		// name is a sheet title
+		r = Range{Sheet: sheetTitle(name)}
+	}
+
+	if r.Min.Row > 0 {
+		return 0, nil
+	}
+
+	props, err := sheetProperties(srv, id, r.Sheet)
+	if err != nil {
+		return 0, fmt.Errorf("frozen rows: %w", err)
+	}
+
+	if props.GridProperties == nil {
+		return 0, nil
+	}
+
+	return int(props.GridProperties.FrozenRowCount), nil
+}
+
+// sheetProperties returns properties of the sheet with given name
+// or of the first sheet if name is empty
+func sheetProperties(srv *sheets.Service, id, name string) (*sheets.SheetProperties, error) {
+	resp, err := srv.Spreadsheets.Get(id).Fields("sheets.properties").Do()
+	if err != nil {
+		return nil, accessError(id, err)
+	}
+
+	for _, sheet := range resp.Sheets {
+		if sheet.Properties == nil {
+			continue
+		}
+
+		if name == "" || sheet.Properties.Title == name {
+			return sheet.Properties, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: '%s'", ErrSheetNotFound, name)
+}
+
+// ResolveNamedRange returns Range of the named range defined in the spreadsheet
+func ResolveNamedRange(srv *sheets.Service, id, name string) (Range, error) {
+	resp, err := srv.Spreadsheets.Get(id).
+		Fields("namedRanges", "sheets.properties(sheetId,title)").
+		Do()
+	if err != nil {
+		return EmptyRange, fmt.Errorf("resolve named range: %w", accessError(id, err))
+	}
+
+	for _, nr := range resp.NamedRanges {
+		if nr.Name != name || nr.Range == nil {
+			continue
+		}
+
+		for _, sheet := range resp.Sheets {
+			if sheet.Properties == nil || sheet.Properties.SheetId != nr.Range.SheetId {
+				continue
+			}
+
+			r, err := FromGridRange(nr.Range, sheet.Properties.Title)
+			if err != nil {
+				return EmptyRange, fmt.Errorf("resolve named range: %v", err)
+			}
+
+			return r, nil
+		}
+
+		return EmptyRange, fmt.Errorf(
+			"resolve named range: %w: id %d", ErrSheetNotFound, nr.Range.SheetId,
+		)
+	}
+
+	return EmptyRange, fmt.Errorf("resolve named range: %w: '%s'", ErrNamedRangeNotFound, name)
+}
@@ -0,0 +1,117 @@
+package spreadsheet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+func TestRangeClipToSheet(t *testing.T) {
+	props, err := json.Marshal(&sheets.Spreadsheet{Sheets: []*sheets.Sheet{
+		{Properties: &sheets.SheetProperties{
+			Title:          "Sheet1",
+			GridProperties: &sheets.GridProperties{RowCount: 1000, ColumnCount: 26},
+		}},
+		{Properties: &sheets.SheetProperties{
+			SheetId:        1,
+			Title:          "Small",
+			GridProperties: &sheets.GridProperties{RowCount: 10, ColumnCount: 3},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("unable to encode properties: %v", err)
+	}
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(props)
+	})
+
+	tt := []struct {
+		rng  string
+		want string
+	}{
+		// range without sheet is clipped to the first sheet
+		{"B5:AZ2303", "B5:Z1000"},
+		{"Sheet1!A1:B2", "Sheet1!A1:B2"},
+		{"Small!B2:Z100", "Small!B2:C10"},
+		{"Small!", "Small!A1:C10"},
+		{"Small!A2:B", "Small!A2:B10"},
+		{"Small!D1:E5", EmptyRange.String()},
+	}
+
+	for _, tc := range tt {
+		r, err := NewRange(tc.rng)
+		if err != nil {
+			t.Fatalf("unable to create range: %v", err)
+		}
+
+		res, err := r.ClipToSheet(srv, "id")
+		if err != nil {
+			t.Errorf("Range{%s}.ClipToSheet() error: %v", tc.rng, err)
+			continue
+		}
+
+		if res.String() != tc.want {
+			t.Errorf("Range{%s}.ClipToSheet() = %v, want %s", tc.rng, res, tc.want)
+		}
+	}
+
+	r, err := NewRange("Missing!A1:B2")
+	if err != nil {
+		t.Fatalf("unable to create range: %v", err)
+	}
+
+	if res, err := r.ClipToSheet(srv, "id"); !errors.Is(err, ErrSheetNotFound) {
+		t.Errorf("Range{Missing!A1:B2}.ClipToSheet() = (%v, %v), want %v", res, err, ErrSheetNotFound)
+	}
+}
+
+func TestResolveNamedRange(t *testing.T) {
+	resp, err := json.Marshal(&sheets.Spreadsheet{
+		NamedRanges: []*sheets.NamedRange{
+			{Name: "Totals", Range: &sheets.GridRange{
+				SheetId: 1, StartRowIndex: 1, EndRowIndex: 5, StartColumnIndex: 0, EndColumnIndex: 2,
+			}},
+			{Name: "Orphan", Range: &sheets.GridRange{SheetId: 7}},
+		},
+		Sheets: []*sheets.Sheet{
+			{Properties: &sheets.SheetProperties{Title: "Sheet1"}},
+			{Properties: &sheets.SheetProperties{SheetId: 1, Title: "Q1 Report"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to encode spreadsheet: %v", err)
+	}
+
+	srv := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(resp)
+	})
+
+	r, err := ResolveNamedRange(srv, "id", "Totals")
+	if err != nil {
+		t.Fatalf("ResolveNamedRange(Totals) error: %v", err)
+	}
+
+	if res := r.String(); res != "'Q1 Report'!A2:B5" {
+		t.Errorf("ResolveNamedRange(Totals) = %s, want 'Q1 Report'!A2:B5", res)
+	}
+
+	if res, err := ResolveNamedRange(srv, "id", "Missing"); !errors.Is(err, ErrNamedRangeNotFound) {
+		t.Errorf("ResolveNamedRange(Missing) = (%v, %v), want %v", res, err, ErrNamedRangeNotFound)
+	}
+
+	if res, err := ResolveNamedRange(srv, "id", "Orphan"); !errors.Is(err, ErrSheetNotFound) {
+		t.Errorf("ResolveNamedRange(Orphan) = (%v, %v), want %v", res, err, ErrSheetNotFound)
+	}
+
+	denied := handlerService(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+
+	if res, err := ResolveNamedRange(denied, "id", "Totals"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("ResolveNamedRange() on denied spreadsheet = (%v, %v), want %v", res, err, ErrPermissionDenied)
+	}
+}
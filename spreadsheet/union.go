@@ -0,0 +1,101 @@
+package spreadsheet
+
+import (
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// CopyUnion copies values of the sheets with given titles to dst as
+// a single table with additional first column named sourceColumn that
+// holds title of the sheet the row was copied from.
+//
+// First row of every sheet is a header. Columns are matched by name,
+// header of the result lists columns in order of their first appearance
+// and cells of columns missing in a sheet are left empty. Columns without
+// name are named by their letters (e.g C), duplicate names within a sheet
+// are suffixed with their number (e.g name_2).
+// Values of all sheets are read before anything is written to dst.
+func CopyUnion(dst CSVWriter, srv *sheets.Service, id string, titles []string, sourceColumn string) error {
+	type part struct {
+		title string
+		cols  []int
+		rows  [][]string
+	}
+
+	var (
+		header []string
+		parts  []part
+		index  = make(map[string]int)
+	)
+
+	for _, title := range titles {
+		rows, err := ReadAll(srv, id, title)
+		if err != nil {
+			return fmt.Errorf("copy union: sheet '%s': %w", title, err)
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+
+		// data rows may be wider than the header,
+		// extra columns are named by their letters
+		names := rows[0]
+		for _, row := range rows[1:] {
+			for len(names) < len(row) {
+				names = append(names, "")
+			}
+		}
+
+		keys, err := jsonKeys(names, true)
+		if err != nil {
+			return fmt.Errorf("copy union: sheet '%s': %v", title, err)
+		}
+
+		cols := make([]int, len(keys))
+		for i, key := range keys {
+			j, ok := index[key]
+			if !ok {
+				j = len(header)
+				index[key] = j
+				header = append(header, key)
+			}
+
+			cols[i] = j
+		}
+
+		parts = append(parts, part{title: title, cols: cols, rows: rows[1:]})
+	}
+
+	if err := dst.Write(append([]string{sourceColumn}, header...)); err != nil {
+		return fmt.Errorf("copy union: %v", err)
+	}
+
+	out := make([]string, len(header)+1)
+
+	for _, p := range parts {
+		for _, row := range p.rows {
+			out[0] = p.title
+			for i := range header {
+				out[i+1] = ""
+			}
+
+			for i, val := range row {
+				out[p.cols[i]+1] = val
+			}
+
+			if err := dst.Write(out); err != nil {
+				return fmt.Errorf("copy union: %v", err)
+			}
+		}
+	}
+
+	dst.Flush()
+
+	if err := dst.Error(); err != nil {
+		return fmt.Errorf("copy union: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCopyUnion(t *testing.T) {
+	srv := fakeService(t, map[string][][]interface{}{
+		"Jan": {
+			{"name", "amount"},
+			{"alice", "10"},
+			{"bob"},
+		},
+		"Feb": {
+			{"amount", "name", "city"},
+			{"20", "carol", "Rome", "extra"},
+		},
+		"Empty": {},
+	})
+
+	want := "sheet,name,amount,city,D\n" +
+		"Jan,alice,10,,\n" +
+		"Jan,bob,,,\n" +
+		"Feb,carol,20,Rome,extra\n"
+
+	var b strings.Builder
+
+	err := CopyUnion(csv.NewWriter(&b), srv, "id", []string{"Jan", "Empty", "Feb"}, "sheet")
+	if err != nil {
+		t.Fatalf("CopyUnion() error: %v", err)
+	}
+
+	if res := b.String(); res != want {
+		t.Errorf("CopyUnion() = %q, want %q", res, want)
+	}
+}
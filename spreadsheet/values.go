@@ -0,0 +1,92 @@
+package spreadsheet
+
+import (
+	"context"
+	"fmt"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// ValuesGetter gets values of the range rng of the spreadsheet id,
+// values are formatted the way Sheets displays them. It is the only
+// request needed by the basic copy, so copy can work with a fake getter
+// in tests instead of the real service.
+type ValuesGetter interface {
+	Get(id, rng string) ([][]interface{}, error)
+}
+
+// NewValuesGetter returns ValuesGetter that requests values with srv,
+// sheet names of the ranges are quoted if needed
+func NewValuesGetter(srv *sheets.Service) ValuesGetter {
+	return serviceGetter{srv: srv, ctx: context.Background()}
+}
+
+// serviceGetter is a ValuesGetter backed by sheets.Service
+type serviceGetter struct {
+	srv *sheets.Service
+	// ctx is a context of the requests, CopyValues sets it
+	// to CopyOptions.Context
+	ctx context.Context
+}
+
+// Get implements ValuesGetter interface
+func (g serviceGetter) Get(id, rng string) ([][]interface{}, error) {
+	resp, err := g.srv.Spreadsheets.Values.Get(id, quoteName(rng)).Context(g.ctx).Do()
+	if err != nil {
+		return nil, accessError(id, err)
+	}
+	return resp.Values, nil
+}
+
+// CopyValues is CopyWithOptions that gets values with src. Options that
+// require other requests than getting of the values (ChunkSize,
+// VerifyComplete, PreserveText, NumberFormat, DryRun and
+// UseFrozenAsHeader) are not supported and are an error.
+// Context is used only by the getter returned by NewValuesGetter.
+func CopyValues(dst CSVWriter, src ValuesGetter, id, name string, opts CopyOptions) error {
+	if name := serviceOption(opts); name != "" {
+		return fmt.Errorf("copy values: %s requires sheets service, use CopyWithOptions", name)
+	}
+
+	if g, ok := src.(serviceGetter); ok {
+		g.ctx = opts.context()
+		src = g
+	}
+
+	fetch := func(rng string, fn func(values [][]interface{}) error) error {
+		values, err := src.Get(id, rng)
+		if err != nil {
+			if opts.Subject != "" {
+				err = fmt.Errorf("failed reading as %s: %w", opts.Subject, err)
+			}
+			return err
+		}
+
+		foldCase(values, opts.CaseFold)
+		return fn(values)
+	}
+
+	return copyRows(dst, opts, func(fn func(int, []interface{}) error) error {
+		return visitFetched(name, opts, fetch, fn)
+	})
+}
+
+// serviceOption returns name of the first option set in opts that
+// requires sheets service, or empty string if there is no such option
+func serviceOption(opts CopyOptions) string {
+	switch {
+	case opts.ChunkSize > 0:
+		return "ChunkSize"
+	case opts.VerifyComplete:
+		return "VerifyComplete"
+	case opts.PreserveText:
+		return "PreserveText"
+	case opts.NumberFormat != nil:
+		return "NumberFormat"
+	case opts.DryRun:
+		return "DryRun"
+	case opts.UseFrozenAsHeader:
+		return "UseFrozenAsHeader"
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package spreadsheet
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeGetter is a ValuesGetter that returns values by the range
+type fakeGetter map[string][][]interface{}
+
+// Get implements ValuesGetter interface
+func (g fakeGetter) Get(id, rng string) ([][]interface{}, error) {
+	values, ok := g[rng]
+	if !ok {
+		return nil, fmt.Errorf("unable to parse range: %s", rng)
+	}
+	return values, nil
+}
+
+func TestCopyValues(t *testing.T) {
+	src := fakeGetter{
+		"Sheet1":     {{"name", "age"}, {"alice", "30"}, {}, {"bob", "25"}, {}},
+		"Sheet1!1:2": {{"name", "age"}, {"alice", "30"}},
+	}
+
+	tt := []struct {
+		opts CopyOptions
+		want string
+	}{
+		{CopyOptions{}, "name,age\nalice,30\n\nbob,25\n\n"},
+		{CopyOptions{TrimEmptyRows: true}, "name,age\nalice,30\n\nbob,25\n"},
+		{CopyOptions{MaxRows: 2, DropSheetHeader: true}, "alice,30\n"},
+	}
+
+	for _, tc := range tt {
+		var b strings.Builder
+
+		if err := CopyValues(csv.NewWriter(&b), src, "id", "Sheet1", tc.opts); err != nil {
+			t.Errorf("CopyValues(%+v) error: %v", tc.opts, err)
+			continue
+		}
+
+		if res := b.String(); res != tc.want {
+			t.Errorf("CopyValues(%+v) = %q, want %q", tc.opts, res, tc.want)
+		}
+	}
+
+	for want, opts := range map[string]CopyOptions{
+		"ChunkSize":         {ChunkSize: 10},
+		"UseFrozenAsHeader": {UseFrozenAsHeader: true},
+		"NumberFormat":      {NumberFormat: func(float64) string { return "" }},
+	} {
+		err := CopyValues(csv.NewWriter(&strings.Builder{}), src, "id", "Sheet1", opts)
+		if err == nil || !strings.Contains(err.Error(), "copy values: "+want+" requires sheets service") {
+			t.Errorf("CopyValues(%+v) error = %v, want %s requires sheets service", opts, err, want)
+		}
+	}
+
+	err := CopyValues(csv.NewWriter(&strings.Builder{}), src, "id", "Sheet2", CopyOptions{Subject: "user@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "failed reading as user@example.com") {
+		t.Errorf("CopyValues(Sheet2) error = %v, want reading as subject error", err)
+	}
+}
+
+func TestCopyValuesContext(t *testing.T) {
+	src := NewValuesGetter(fakeService(t, map[string][][]interface{}{"Sheet1": {{"a"}}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := CopyValues(csv.NewWriter(&strings.Builder{}), src, "id", "Sheet1", CopyOptions{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CopyValues(cancelled context) error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,143 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// errInvalidDelim is returned by quoteAllWriter when delimiter is not valid
+var errInvalidDelim = fmt.Errorf("csv: invalid field delimiter")
+
+// newCSVWriter creates CSVWriter for w configured by options
+func newCSVWriter(w io.Writer, opts CopyOptions) CSVWriter {
+	comma := ','
+	if opts.Delimiter != 0 {
+		comma = opts.Delimiter
+	}
+
+	if opts.QuoteAll {
+		return &quoteAllWriter{w: bufio.NewWriter(w), comma: comma, useCRLF: opts.UseCRLF}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	cw.UseCRLF = opts.UseCRLF
+
+	return cw
+}
+
+// withChecksum returns writer that also computes SHA-256 of the bytes
+// written to w if opts.Checksum is set, done stores the checksum
+// to opts.Checksum
+func withChecksum(w io.Writer, opts CopyOptions) (io.Writer, func()) {
+	if opts.Checksum == nil {
+		return w, func() {}
+	}
+
+	h := sha256.New()
+
+	return io.MultiWriter(w, h), func() {
+		*opts.Checksum = hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// validDelim checks if rune can be used as a field delimiter,
+// same rules as in encoding/csv
+func validDelim(r rune) bool {
+	return r != 0 && r != '"' && r != '\r' && r != '\n' &&
+		utf8.ValidRune(r) && r != utf8.RuneError
+}
+
+// sanitizeFormulas prefixes fields of the row that may be evaluated as
+// formulas with guard or with single quote if guard is empty
+func sanitizeFormulas(row []string, guard string) {
+	if guard == "" {
+		guard = "'"
+	}
+
+	for i, field := range row {
+		if field != "" && strings.IndexByte("=+-@\t\r", field[0]) >= 0 {
+			row[i] = guard + field
+		}
+	}
+}
+
+// validateUTF8 replaces invalid UTF-8 sequences in the fields of the row
+// with U+FFFD or returns error if reject is true
+func validateUTF8(row []string, reject bool) error {
+	for i, field := range row {
+		if utf8.ValidString(field) {
+			continue
+		}
+
+		if reject {
+			return fmt.Errorf("column %s: %w", string(colRunes(i+1)), ErrInvalidUTF8)
+		}
+
+		row[i] = strings.ToValidUTF8(field, "\uFFFD")
+	}
+
+	return nil
+}
+
+// quoteAllWriter is a CSVWriter that quotes every field.
+//
+// csv.Writer decides by itself which fields need quotes and there is no
+// way to force it, pre-quoting fields does not help either since
+// csv.Writer escapes the added quotes.
+type quoteAllWriter struct {
+	w     *bufio.Writer
+	comma rune
+	// useCRLF ends records with \r\n, line breaks inside
+	// of the fields are written as is
+	useCRLF bool
+	err     error
+}
+
+// Error implements CSVWriter interface
+func (q *quoteAllWriter) Error() error {
+	return q.err
+}
+
+// Flush implements CSVWriter interface
+func (q *quoteAllWriter) Flush() {
+	if q.err == nil {
+		q.err = q.w.Flush()
+	}
+}
+
+// Write implements CSVWriter interface
+func (q *quoteAllWriter) Write(record []string) error {
+	if q.err != nil {
+		return q.err
+	}
+
+	if !validDelim(q.comma) {
+		return errInvalidDelim
+	}
+
+	for i, field := range record {
+		if i > 0 {
+			q.w.WriteRune(q.comma)
+		}
+
+		q.w.WriteByte('"')
+		q.w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		q.w.WriteByte('"')
+	}
+
+	if q.useCRLF {
+		q.w.WriteByte('\r')
+	}
+
+	// bufio.Writer keeps first error and returns it from every write
+	_, q.err = q.w.WriteString("\n")
+
+	return q.err
+}
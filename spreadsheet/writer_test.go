@@ -0,0 +1,121 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewCSVWriter(t *testing.T) {
+	records := [][]string{
+		{"id", "zip", "name"},
+		{"007", "01234", `John "Jack" Doe`},
+		{"", "1,5"},
+	}
+
+	tt := []struct {
+		opts CopyOptions
+		want string
+	}{
+		{
+			CopyOptions{},
+			"id,zip,name\n007,01234,\"John \"\"Jack\"\" Doe\"\n,\"1,5\"\n",
+		},
+		{
+			CopyOptions{QuoteAll: true},
+			"\"id\",\"zip\",\"name\"\n\"007\",\"01234\",\"John \"\"Jack\"\" Doe\"\n\"\",\"1,5\"\n",
+		},
+		{
+			CopyOptions{Delimiter: '\t'},
+			"id\tzip\tname\n007\t01234\t\"John \"\"Jack\"\" Doe\"\n\t1,5\n",
+		},
+		{
+			CopyOptions{Delimiter: ';', QuoteAll: true},
+			"\"id\";\"zip\";\"name\"\n\"007\";\"01234\";\"John \"\"Jack\"\" Doe\"\n\"\";\"1,5\"\n",
+		},
+		{
+			CopyOptions{UseCRLF: true},
+			"id,zip,name\r\n007,01234,\"John \"\"Jack\"\" Doe\"\r\n,\"1,5\"\r\n",
+		},
+		{
+			CopyOptions{UseCRLF: true, QuoteAll: true},
+			"\"id\",\"zip\",\"name\"\r\n\"007\",\"01234\",\"John \"\"Jack\"\" Doe\"\r\n\"\",\"1,5\"\r\n",
+		},
+	}
+
+	for _, tc := range tt {
+		var buf bytes.Buffer
+
+		w := newCSVWriter(&buf, tc.opts)
+		for _, rec := range records {
+			if err := w.Write(rec); err != nil {
+				t.Fatalf("%+v: Write(%q) = %v", tc.opts, rec, err)
+			}
+		}
+		w.Flush()
+
+		if err := w.Error(); err != nil {
+			t.Errorf("%+v: Error() = %v", tc.opts, err)
+		}
+
+		if res := buf.String(); res != tc.want {
+			t.Errorf("%+v: result %q, want %q", tc.opts, res, tc.want)
+		}
+	}
+}
+
+func TestNewCSVWriterInvalidDelimiter(t *testing.T) {
+	for _, opts := range []CopyOptions{
+		{Delimiter: '"'},
+		{Delimiter: '\n', QuoteAll: true},
+	} {
+		var buf bytes.Buffer
+
+		if err := newCSVWriter(&buf, opts).Write([]string{"a"}); err == nil {
+			t.Errorf("%+v: Write() = nil, want error", opts)
+		}
+	}
+}
+
+func TestSanitizeFormulas(t *testing.T) {
+	tt := []struct {
+		row   []string
+		guard string
+		want  []string
+	}{
+		{
+			[]string{"=1+2", "+1", "-1", "@SUM(A1)", "\tx", "\rx", "a=b", ""},
+			"",
+			[]string{"'=1+2", "'+1", "'-1", "'@SUM(A1)", "'\tx", "'\rx", "a=b", ""},
+		},
+		{[]string{"=HYPERLINK()", "ok"}, " ", []string{" =HYPERLINK()", "ok"}},
+	}
+
+	for _, tc := range tt {
+		row := append([]string(nil), tc.row...)
+		sanitizeFormulas(row, tc.guard)
+
+		if strings.Join(row, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("sanitizeFormulas(%q, %q) = %q, want %q", tc.row, tc.guard, row, tc.want)
+		}
+	}
+}
+
+func TestValidateUTF8(t *testing.T) {
+	row := []string{"ok", "a\xffb", "\xc3\x28"}
+	want := []string{"ok", "a\uFFFDb", "\uFFFD("}
+
+	if err := validateUTF8(row, false); err != nil {
+		t.Fatalf("validateUTF8(%q, false) error: %v", row, err)
+	}
+
+	if strings.Join(row, "|") != strings.Join(want, "|") {
+		t.Errorf("validateUTF8() = %q, want %q", row, want)
+	}
+
+	err := validateUTF8([]string{"ok", "a\xffb"}, true)
+	if !errors.Is(err, ErrInvalidUTF8) || !strings.Contains(err.Error(), "column B") {
+		t.Errorf("validateUTF8(reject) = %v, want %v in column B", err, ErrInvalidUTF8)
+	}
+}